@@ -0,0 +1,84 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+)
+
+const defaultBlockingPoolSize = 4
+
+// BlockingClient — returns the client dedicated to blocking commands such as XREAD BLOCK or BLMOVE.
+// It owns its own pool of ConnectConfig.BlockingPoolSize connections, so long blocks cannot starve
+// regular commands; in exchange they queue behind each other once that many are in flight,
+// and the server sees up to that many extra connections per service (per node in cluster mode).
+// The client is raw, so BlockingClient returns ErrNotNamespaced while ConnectConfig.TenantExtractor is set
+// and ErrReadOnlyMode while ConnectConfig.ReadOnly is set.
+func (s *Service) BlockingClient() (client rdb.UniversalClient, err error) {
+	defer s.reportReturned("BlockingClient", "", &err)
+
+	if err := s.requireNamespace("BlockingClient"); err != nil {
+		return nil, err
+	}
+
+	if s.c.ReadOnly {
+		s.l.WarnT(s.traceName, "Rejected raw blocking client in read-only mode")
+		return nil, fmt.Errorf("%w: BlockingClient", ErrReadOnlyMode)
+	}
+
+	return s.blocking, nil
+}
+
+// BLPop — pops the first element of the first non-empty list among keys, waiting up to timeout (0 waits forever).
+// Runs on the blocking client, see BlockingClient. Returns the key and the element, or ErrTimeout.
+func (s *Service) BLPop(ctx *eactx.Context, timeout time.Duration, keys ...string) (key string, value string, err error) {
+	defer s.reportReturned("BLPop", "", &err)
+
+	if err := s.checkCommand("BLPOP"); err != nil {
+		return "", "", err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.blockingPop(s.blocking.BLPop(ctx.GetContext(), timeout, prefixed(tenant, keys)...), tenant, keys)
+}
+
+// BRPop — the BLPop twin popping the last element.
+func (s *Service) BRPop(ctx *eactx.Context, timeout time.Duration, keys ...string) (key string, value string, err error) {
+	defer s.reportReturned("BRPop", "", &err)
+
+	if err := s.checkCommand("BRPOP"); err != nil {
+		return "", "", err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	return s.blockingPop(s.blocking.BRPop(ctx.GetContext(), timeout, prefixed(tenant, keys)...), tenant, keys)
+}
+
+func (s *Service) blockingPop(cmd *rdb.StringSliceCmd, tenant string, keys []string) (string, string, error) {
+	result, err := cmd.Result()
+	if errors.Is(err, rdb.Nil) {
+		return "", "", fmt.Errorf("%w: popping %v", ErrTimeout, keys)
+	}
+
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to pop from keys", keys, err)
+		return "", "", err
+	}
+
+	if len(result) != 2 {
+		return "", "", fmt.Errorf("earedis: unexpected pop reply %v", result)
+	}
+
+	return strings.TrimPrefix(result[0], tenant), result[1], nil
+}
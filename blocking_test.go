@@ -0,0 +1,63 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBLPopDoesNotStarveGet(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) { c.BlockingPoolSize = 2 })
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "plain", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	const poppers = 4
+
+	var wg sync.WaitGroup
+	for i := 0; i < poppers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			key, value, err := s.BLPop(ctx, 10*time.Second, fmt.Sprintf("jobs:%d", i))
+			if err != nil || key != fmt.Sprintf("jobs:%d", i) || value != "job" {
+				t.Errorf("BLPop %d = %s %q, %v", i, key, value, err)
+			}
+		}(i)
+	}
+
+	// Give the poppers time to block and exhaust the blocking pool.
+	time.Sleep(200 * time.Millisecond)
+
+	started := time.Now()
+	for i := 0; i < 10; i++ {
+		if got, err := s.Get(ctx, "plain"); err != nil || got != "value" {
+			t.Fatalf("Get = %q, %v; want \"value\"", got, err)
+		}
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Get took %v while BLPops were blocked", elapsed)
+	}
+
+	for i := 0; i < poppers; i++ {
+		if err := s.client.RPush(ctx.GetContext(), fmt.Sprintf("jobs:%d", i), "job").Err(); err != nil {
+			t.Fatalf("RPUSH: %v", err)
+		}
+	}
+
+	wg.Wait()
+}
+
+func TestBLPopTimeout(t *testing.T) {
+	s := newTestService(t)
+
+	if _, _, err := s.BLPop(testContext(t), 100*time.Millisecond, "empty"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("BLPop error = %v, want ErrTimeout", err)
+	}
+}
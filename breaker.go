@@ -0,0 +1,144 @@
+package earedis
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	rdb "github.com/redis/go-redis/v9"
+	"sync"
+	"time"
+)
+
+const defaultKeyBreakerMaxKeys = 1024
+
+// KeyBreakerConfig — settings of the per-key circuit breaker, which short-circuits a single failing
+// (poison) key while every other key keeps flowing. Disabled when Threshold is 0.
+type KeyBreakerConfig struct {
+	// Threshold — consecutive failures of a key that open its breaker.
+	Threshold int
+	// Cooldown — how long an open key is rejected with ErrKeyCircuitOpen before one trial call is let through;
+	// the others keep being rejected until the trial succeeds, or for another Cooldown if it never reports back.
+	Cooldown time.Duration
+	// MaxKeys — failing keys tracked at most; the least recently failing are forgotten first. Defaults to 1024.
+	MaxKeys int
+}
+
+// breakerEntry — the failure state of one key.
+type breakerEntry struct {
+	key       string
+	failures  int
+	openUntil time.Time
+}
+
+// keyBreaker — a bounded LRU of failing keys.
+type keyBreaker struct {
+	c     KeyBreakerConfig
+	clock func() Clock
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+func newKeyBreaker(c KeyBreakerConfig, clock func() Clock) *keyBreaker {
+	if c.MaxKeys <= 0 {
+		c.MaxKeys = defaultKeyBreakerMaxKeys
+	}
+
+	return &keyBreaker{
+		c:     c,
+		clock: clock,
+
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// allow — returns ErrKeyCircuitOpen while the breaker of key is open. Once the cooldown has passed a single
+// trial call is let through, and the breaker stays open for everyone else until the trial is recorded.
+func (b *keyBreaker) allow(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	el, ok := b.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := el.Value.(*breakerEntry)
+	if entry.failures < b.c.Threshold {
+		return nil
+	}
+
+	now := b.clock().Now()
+	if now.Before(entry.openUntil) {
+		return fmt.Errorf("%w: %s", ErrKeyCircuitOpen, key)
+	}
+
+	// Half-open: this caller is the trial, the next ones wait for its outcome.
+	entry.openUntil = now.Add(b.c.Cooldown)
+	return nil
+}
+
+// record — forgets key on success and counts a failure otherwise, opening its breaker at the threshold.
+// Misses and caller cancellations are not failures of the key.
+func (b *keyBreaker) record(key string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A caller giving up says nothing about the key; a cancelled trial leaves the breaker open.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if err == nil || errors.Is(err, rdb.Nil) {
+		if el, ok := b.entries[key]; ok {
+			b.lru.Remove(el)
+			delete(b.entries, key)
+		}
+
+		return
+	}
+
+	el, ok := b.entries[key]
+	if ok {
+		b.lru.MoveToFront(el)
+	} else {
+		el = b.lru.PushFront(&breakerEntry{key: key})
+		b.entries[key] = el
+
+		if b.lru.Len() > b.c.MaxKeys {
+			oldest := b.lru.Back()
+			b.lru.Remove(oldest)
+			delete(b.entries, oldest.Value.(*breakerEntry).key)
+		}
+	}
+
+	entry := el.Value.(*breakerEntry)
+	entry.failures++
+	if entry.failures >= b.c.Threshold {
+		entry.openUntil = b.clock().Now().Add(b.c.Cooldown)
+	}
+}
+
+// checkKey — rejects a key whose breaker is open.
+func (s *Service) checkKey(key string) error {
+	if s.breaker == nil {
+		return nil
+	}
+
+	if err := s.breaker.allow(key); err != nil {
+		s.l.WarnT(s.traceName, "Short-circuited failing key", key)
+		return err
+	}
+
+	return nil
+}
+
+// recordKey — reports the outcome of a command on key to its breaker.
+func (s *Service) recordKey(key string, err error) {
+	if s.breaker != nil {
+		s.breaker.record(key, err)
+	}
+}
@@ -0,0 +1,138 @@
+package earedis
+
+import (
+	"context"
+	"errors"
+	rdb "github.com/redis/go-redis/v9"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failKeyHook — fails every command on key without sending it, counting the attempts.
+type failKeyHook struct {
+	key      string
+	failing  atomic.Bool
+	attempts atomic.Int64
+}
+
+func (h *failKeyHook) DialHook(next rdb.DialHook) rdb.DialHook {
+	return next
+}
+
+func (h *failKeyHook) ProcessHook(next rdb.ProcessHook) rdb.ProcessHook {
+	return func(ctx context.Context, cmd rdb.Cmder) error {
+		if commandKey(cmd) != h.key {
+			return next(ctx, cmd)
+		}
+
+		h.attempts.Add(1)
+		if !h.failing.Load() {
+			return next(ctx, cmd)
+		}
+
+		err := errors.New("injected failure")
+		cmd.SetErr(err)
+		return err
+	}
+}
+
+func (h *failKeyHook) ProcessPipelineHook(next rdb.ProcessPipelineHook) rdb.ProcessPipelineHook {
+	return next
+}
+
+func TestKeyBreakerShortCircuitsPoisonKey(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.KeyBreaker = KeyBreakerConfig{Threshold: 3, Cooldown: time.Minute}
+	})
+	ctx := testContext(t)
+
+	clock := &fakeClock{now: time.Now()}
+	s.SetClock(clock)
+
+	if err := s.Set(ctx, "healthy", "ok", 0); err != nil {
+		t.Fatalf("Set healthy: %v", err)
+	}
+
+	if err := s.Set(ctx, "poison", "value", 0); err != nil {
+		t.Fatalf("Set poison: %v", err)
+	}
+
+	hook := &failKeyHook{key: "poison"}
+	hook.failing.Store(true)
+	s.client.AddHook(hook)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Get(ctx, "poison"); err == nil || errors.Is(err, ErrKeyCircuitOpen) {
+			t.Fatalf("Get poison %d: %v, want the injected failure", i, err)
+		}
+	}
+
+	if _, err := s.Get(ctx, "poison"); !errors.Is(err, ErrKeyCircuitOpen) {
+		t.Fatalf("Get poison after the threshold: %v, want ErrKeyCircuitOpen", err)
+	}
+
+	if hook.attempts.Load() != 3 {
+		t.Fatalf("poison reached redis %d times, want 3", hook.attempts.Load())
+	}
+
+	if got, err := s.Get(ctx, "healthy"); err != nil || got != "ok" {
+		t.Fatalf("Get healthy = %q, %v; want \"ok\"", got, err)
+	}
+
+	// After the cooldown exactly one trial is let through.
+	clock.advance(2 * time.Minute)
+	if err := s.checkKey("poison"); err != nil {
+		t.Fatalf("trial after the cooldown rejected: %v", err)
+	}
+
+	if err := s.checkKey("poison"); !errors.Is(err, ErrKeyCircuitOpen) {
+		t.Fatalf("second caller during the trial: %v, want ErrKeyCircuitOpen", err)
+	}
+
+	// A successful trial closes the breaker.
+	hook.failing.Store(false)
+	clock.advance(2 * time.Minute)
+	if got, err := s.Get(ctx, "poison"); err != nil || got != "value" {
+		t.Fatalf("trial Get poison = %q, %v; want \"value\"", got, err)
+	}
+
+	if got, err := s.Get(ctx, "poison"); err != nil || got != "value" {
+		t.Fatalf("Get poison after recovery = %q, %v; want \"value\"", got, err)
+	}
+}
+
+func TestKeyBreakerCancelledTrialKeepsBreakerOpen(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	b := newKeyBreaker(KeyBreakerConfig{Threshold: 2, Cooldown: time.Minute}, func() Clock { return clock })
+
+	failure := errors.New("injected failure")
+	b.record("poison", failure)
+	b.record("poison", failure)
+
+	if err := b.allow("poison"); !errors.Is(err, ErrKeyCircuitOpen) {
+		t.Fatalf("allow after the threshold: %v, want ErrKeyCircuitOpen", err)
+	}
+
+	clock.advance(2 * time.Minute)
+	if err := b.allow("poison"); err != nil {
+		t.Fatalf("trial after the cooldown rejected: %v", err)
+	}
+
+	// The trial caller gives up before redis answers.
+	b.record("poison", context.Canceled)
+
+	if err := b.allow("poison"); !errors.Is(err, ErrKeyCircuitOpen) {
+		t.Fatalf("allow after a cancelled trial: %v, want ErrKeyCircuitOpen", err)
+	}
+
+	clock.advance(2 * time.Minute)
+	if err := b.allow("poison"); err != nil {
+		t.Fatalf("next trial rejected: %v", err)
+	}
+
+	b.record("poison", nil)
+	if err := b.allow("poison"); err != nil {
+		t.Fatalf("allow after a successful trial: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package earedis
+
+import (
+	"fmt"
+	"github.com/eris-apple/eactx"
+	"strconv"
+	"strings"
+)
+
+// ServerCapabilities — what the server announced at Init; the zero value means it could not be determined.
+type ServerCapabilities struct {
+	// Version — the server version, e.g. "7.2.4".
+	Version string
+	// Protocol — the RESP protocol version in use, 2 or 3.
+	Protocol int
+	// Mode — "standalone", "cluster" or "sentinel".
+	Mode string
+	// Modules — the names of the loaded modules, e.g. "ReJSON".
+	Modules []string
+}
+
+// AtLeast — reports whether the server version is major.minor or newer.
+// An unknown version is optimistically reported as new enough, leaving the decision to the server.
+func (c ServerCapabilities) AtLeast(major, minor int) bool {
+	if c.Version == "" {
+		return true
+	}
+
+	parts := strings.SplitN(c.Version, ".", 3)
+	gotMajor, _ := strconv.Atoi(parts[0])
+	gotMinor := 0
+	if len(parts) > 1 {
+		gotMinor, _ = strconv.Atoi(parts[1])
+	}
+
+	return gotMajor > major || gotMajor == major && gotMinor >= minor
+}
+
+// HasModule — reports whether the module is loaded. Unknown capabilities are reported as loaded.
+func (c ServerCapabilities) HasModule(name string) bool {
+	if c.Version == "" {
+		return true
+	}
+
+	for _, module := range c.Modules {
+		if strings.EqualFold(module, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ServerCapabilities — returns the capabilities cached at Init.
+func (s *Service) ServerCapabilities() ServerCapabilities {
+	return s.capabilities
+}
+
+// requireVersion — returns ErrUnsupported when the server is known to be older than major.minor.
+func (s *Service) requireVersion(command string, major, minor int) error {
+	if s.capabilities.AtLeast(major, minor) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s requires redis %d.%d+, server is %s", ErrUnsupported, command, major, minor, s.capabilities.Version)
+}
+
+// requireModule — returns ErrModuleNotLoaded when the module is known to be absent.
+func (s *Service) requireModule(name string) error {
+	if s.capabilities.HasModule(name) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrModuleNotLoaded, name)
+}
+
+// probeCapabilities — reads the server capabilities with HELLO, falling back to INFO server on servers before 6.0.
+func (s *Service) probeCapabilities(ctx *eactx.Context) (ServerCapabilities, error) {
+	reply, err := s.client.Do(ctx.GetContext(), "hello").Result()
+	if err != nil {
+		info, infoErr := s.client.Info(ctx.GetContext(), "server").Result()
+		if infoErr != nil {
+			return ServerCapabilities{}, err
+		}
+
+		fields := parseInfo(info)
+		return ServerCapabilities{Version: fields["redis_version"], Protocol: 2, Mode: fields["redis_mode"]}, nil
+	}
+
+	hello, ok := replyMap(reply)
+	if !ok {
+		return ServerCapabilities{}, fmt.Errorf("earedis: unexpected HELLO reply %T", reply)
+	}
+
+	caps := ServerCapabilities{}
+	caps.Version, _ = hello["version"].(string)
+	caps.Mode, _ = hello["mode"].(string)
+
+	if proto, ok := hello["proto"].(int64); ok {
+		caps.Protocol = int(proto)
+	}
+
+	if modules, ok := hello["modules"].([]interface{}); ok {
+		for _, module := range modules {
+			if fields, ok := replyMap(module); ok {
+				caps.Modules = append(caps.Modules, fmt.Sprint(fields["name"]))
+			}
+		}
+	}
+
+	return caps, nil
+}
+
+// replyMap — converts a map reply, received as a RESP3 map or a RESP2 flat array of pairs, into a string-keyed map.
+func replyMap(reply interface{}) (map[string]interface{}, bool) {
+	result := make(map[string]interface{})
+
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		for name, value := range reply {
+			result[fmt.Sprint(name)] = value
+		}
+	case []interface{}:
+		for i := 0; i+1 < len(reply); i += 2 {
+			result[fmt.Sprint(reply[i])] = reply[i+1]
+		}
+	default:
+		return nil, false
+	}
+
+	return result, true
+}
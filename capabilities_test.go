@@ -0,0 +1,92 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCapabilitiesAfterInit(t *testing.T) {
+	s := newTestService(t)
+
+	if err := s.client.Do(testContext(t).GetContext(), "hello").Err(); err != nil {
+		t.Skipf("the test server does not answer HELLO: %v", err)
+	}
+
+	caps := s.ServerCapabilities()
+	if caps.Version == "" {
+		t.Fatal("Init cached no server version")
+	}
+
+	if caps.Protocol != 2 && caps.Protocol != 3 {
+		t.Fatalf("Protocol = %d, want 2 or 3", caps.Protocol)
+	}
+
+	if caps.Mode != "standalone" {
+		t.Fatalf("Mode = %q, want standalone", caps.Mode)
+	}
+
+	if !caps.AtLeast(1, 0) || caps.AtLeast(1000, 0) {
+		t.Fatalf("AtLeast misjudges version %s", caps.Version)
+	}
+}
+
+func TestServerCapabilitiesAtLeast(t *testing.T) {
+	tests := []struct {
+		version      string
+		major, minor int
+		want         bool
+	}{
+		{"7.2.4", 7, 2, true},
+		{"7.2.4", 7, 4, false},
+		{"7.4.0", 7, 2, true},
+		{"6.2.14", 7, 0, false},
+		{"8.0.0", 7, 4, true},
+		{"7", 7, 0, true},
+		{"7", 7, 2, false},
+		// An unknown version leaves the decision to the server.
+		{"", 99, 0, true},
+	}
+
+	for _, tt := range tests {
+		caps := ServerCapabilities{Version: tt.version}
+		if got := caps.AtLeast(tt.major, tt.minor); got != tt.want {
+			t.Errorf("ServerCapabilities{%q}.AtLeast(%d, %d) = %v, want %v", tt.version, tt.major, tt.minor, got, tt.want)
+		}
+	}
+
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+	s.capabilities = ServerCapabilities{Version: "6.2.14"}
+	if err := s.requireVersion("WAITAOF", 7, 2); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("requireVersion on 6.2: %v, want ErrUnsupported", err)
+	}
+}
+
+func TestServerCapabilitiesHasModule(t *testing.T) {
+	caps := ServerCapabilities{Version: "7.2.4", Modules: []string{"ReJSON", "search"}}
+	if !caps.HasModule("rejson") || !caps.HasModule("search") || caps.HasModule("timeseries") {
+		t.Fatalf("HasModule misreports modules %v", caps.Modules)
+	}
+
+	if !(ServerCapabilities{}).HasModule("timeseries") {
+		t.Fatal("unknown capabilities must report modules as loaded")
+	}
+}
+
+func TestReplyMap(t *testing.T) {
+	resp3 := map[interface{}]interface{}{"server": "redis", "proto": int64(3)}
+	resp2 := []interface{}{"server", "redis", "proto", int64(2), "dangling"}
+
+	for _, tt := range []struct {
+		reply interface{}
+		proto int64
+	}{{resp3, 3}, {resp2, 2}} {
+		m, ok := replyMap(tt.reply)
+		if !ok || m["server"] != "redis" || m["proto"] != tt.proto || len(m) != 2 {
+			t.Fatalf("replyMap(%v) = %v, %v", tt.reply, m, ok)
+		}
+	}
+
+	if _, ok := replyMap("OK"); ok {
+		t.Fatal("replyMap accepted a status reply")
+	}
+}
@@ -0,0 +1,25 @@
+package earedis
+
+import "time"
+
+// Clock — the source of the current time for every time-dependent operation of the service.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock — the Clock backed by time.Now.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// SetClock — replaces the clock used by the service after construction, like ConnectConfig.Clock.
+// A nil clock restores the real one.
+func (s *Service) SetClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	s.clock = clock
+}
@@ -0,0 +1,43 @@
+package earedis
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock — a Clock frozen at a time moved only by advance.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// advance — moves the clock forward by d.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestInitTimesOutOnRealTime(t *testing.T) {
+	ttl := 200 * time.Millisecond
+
+	// A non-routable address, so the ping hangs until the timeout.
+	clock := &fakeClock{now: time.Now().Add(24 * time.Hour)}
+	s := NewService(testLogger, &ConnectConfig{Addr: "10.255.255.1:6379", pingConnectionTTL: &ttl, Clock: clock}, "test")
+
+	started := time.Now()
+	if err := s.Init(); err == nil {
+		t.Fatal("Init succeeded against an unreachable address")
+	}
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("Init took %v, want about %v", elapsed, ttl)
+	}
+}
@@ -0,0 +1,77 @@
+package earedis
+
+import (
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+type ClusterSlot = rdb.ClusterSlot
+
+// isCluster — reports whether the service is connected in cluster mode.
+func (s *Service) isCluster() bool {
+	_, ok := s.client.(*rdb.ClusterClient)
+	return ok
+}
+
+// ClusterNodes — returns the raw CLUSTER NODES topology description.
+func (s *Service) ClusterNodes(ctx *eactx.Context) (nodes string, err error) {
+	defer s.reportReturned("ClusterNodes", "", &err)
+
+	if err := s.checkCommand("CLUSTER NODES"); err != nil {
+		return "", err
+	}
+
+	if !s.isCluster() {
+		return "", ErrNotClusterMode
+	}
+
+	result, err := s.client.ClusterNodes(ctx.GetContext()).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get cluster nodes", err)
+		return "", err
+	}
+
+	return result, nil
+}
+
+// ClusterInfo — returns the CLUSTER INFO fields, e.g. "cluster_state" and "cluster_slots_assigned".
+func (s *Service) ClusterInfo(ctx *eactx.Context) (info map[string]string, err error) {
+	defer s.reportReturned("ClusterInfo", "", &err)
+
+	if err := s.checkCommand("CLUSTER INFO"); err != nil {
+		return nil, err
+	}
+
+	if !s.isCluster() {
+		return nil, ErrNotClusterMode
+	}
+
+	result, err := s.client.ClusterInfo(ctx.GetContext()).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get cluster info", err)
+		return nil, err
+	}
+
+	return parseInfo(result), nil
+}
+
+// ClusterSlots — returns the slot ranges and the nodes serving them.
+func (s *Service) ClusterSlots(ctx *eactx.Context) (slots []ClusterSlot, err error) {
+	defer s.reportReturned("ClusterSlots", "", &err)
+
+	if err := s.checkCommand("CLUSTER SLOTS"); err != nil {
+		return nil, err
+	}
+
+	if !s.isCluster() {
+		return nil, ErrNotClusterMode
+	}
+
+	result, err := s.client.ClusterSlots(ctx.GetContext()).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get cluster slots", err)
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package earedis
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestClusterInfo(t *testing.T) {
+	s := newTestClusterService(t)
+	ctx := testContext(t)
+
+	info, err := s.ClusterInfo(ctx)
+	if err != nil {
+		t.Fatalf("ClusterInfo: %v", err)
+	}
+
+	if info["cluster_state"] != "ok" {
+		t.Fatalf("cluster_state = %q, want ok", info["cluster_state"])
+	}
+
+	if assigned, err := strconv.Atoi(info["cluster_slots_assigned"]); err != nil || assigned != 16384 {
+		t.Fatalf("cluster_slots_assigned = %q, want 16384", info["cluster_slots_assigned"])
+	}
+
+	if known, err := strconv.Atoi(info["cluster_known_nodes"]); err != nil || known < 1 {
+		t.Fatalf("cluster_known_nodes = %q, want at least 1", info["cluster_known_nodes"])
+	}
+}
+
+func TestClusterInfoStandalone(t *testing.T) {
+	s := newTestService(t)
+
+	if _, err := s.ClusterInfo(testContext(t)); !errors.Is(err, ErrNotClusterMode) {
+		t.Fatalf("ClusterInfo error = %v, want ErrNotClusterMode", err)
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	info := parseInfo("# Cluster\r\ncluster_state:ok\r\ncluster_slots_assigned:16384\r\n\r\nmalformed\r\n")
+
+	if len(info) != 2 || info["cluster_state"] != "ok" || info["cluster_slots_assigned"] != "16384" {
+		t.Fatalf("parseInfo = %v", info)
+	}
+}
@@ -0,0 +1,202 @@
+package earedis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"io"
+	"strconv"
+	"sync/atomic"
+)
+
+// Codec — serializes values stored in redis.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec — the Codec backed by encoding/json.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+const migrateScanCount = 500
+
+// MigrateCodec — re-encodes the string values of every key matching pattern from one codec to another,
+// returning the number of migrated keys. Values are decoded into a generic interface{} and written back
+// with KEEPTTL, so expirations are preserved. JSONCodec values keep their numbers exact, integers above 2^53 included. Keys that are not strings or fail to decode are logged and skipped.
+// A key written concurrently between the read and the write-back may lose that write.
+func (s *Service) MigrateCodec(ctx *eactx.Context, pattern string, from, to Codec) (count int, err error) {
+	defer s.reportReturned("MigrateCodec", "", &err)
+
+	if err := s.checkCommand("SET"); err != nil {
+		return 0, err
+	}
+
+	pattern, err = s.tenantKey(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	var migrated atomic.Int64
+
+	err = s.forEachNode(ctx.GetContext(), func(ctx context.Context, client rdb.Cmdable) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, migrateScanCount).Result()
+			if err != nil {
+				return err
+			}
+
+			n, err := s.migrateKeys(ctx, client, keys, from, to)
+			if err != nil {
+				return err
+			}
+
+			migrated.Add(int64(n))
+			cursor = next
+
+			if cursor == 0 {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to migrate codec by pattern", pattern, err)
+		return int(migrated.Load()), err
+	}
+
+	s.l.InfoT(s.traceName, "Migrated codec by pattern", pattern, migrated.Load())
+	return int(migrated.Load()), nil
+}
+
+// migrateKeys — re-encodes one scanned batch, reading and then writing it in pipelines
+// of at most ConnectConfig.MaxPipelineSize commands.
+func (s *Service) migrateKeys(ctx context.Context, client rdb.Cmdable, keys []string, from, to Codec) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	gets := make([]*rdb.StringCmd, len(keys))
+	err := s.pipelined(ctx, client, len(keys), func(pipe rdb.Pipeliner, i int) {
+		gets[i] = pipe.Get(ctx, keys[i])
+	})
+	if err != nil && !isPerCommandError(err) {
+		return 0, err
+	}
+
+	sets := make([]*rdb.StatusCmd, 0, len(keys))
+	err = s.pipelined(ctx, client, len(keys), func(pipe rdb.Pipeliner, i int) {
+		key := keys[i]
+
+		data, err := gets[i].Bytes()
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to read key for migration", key, err)
+			return
+		}
+
+		v, err := decodeGeneric(data, from, to)
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to decode key for migration", key, err)
+			return
+		}
+
+		encoded, err := to.Marshal(v)
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to encode key for migration", key, err)
+			return
+		}
+
+		if err := s.checkValueSize(key, encoded); err != nil {
+			return
+		}
+
+		sets = append(sets, pipe.SetArgs(ctx, key, encoded, rdb.SetArgs{KeepTTL: true}))
+	})
+	if err != nil && !isPerCommandError(err) {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, cmd := range sets {
+		if err := cmd.Err(); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to write key for migration", cmd.Args()[1], err)
+			continue
+		}
+
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// decodeGeneric — decodes data with from into a generic value to be encoded with to. JSONCodec decodes numbers
+// as json.Number, so integers above 2^53 are not rounded through float64; for any other target codec they are
+// converted to int64, uint64 or float64, the types every codec understands.
+func decodeGeneric(data []byte, from, to Codec) (interface{}, error) {
+	var v interface{}
+	if _, ok := from.(JSONCodec); !ok {
+		err := from.Unmarshal(data, &v)
+		return v, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	// Like json.Unmarshal, reject anything after the value.
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("earedis: invalid JSON: data after the top-level value")
+	}
+
+	if _, ok := to.(JSONCodec); ok {
+		return v, nil
+	}
+
+	return plainNumbers(v), nil
+}
+
+// plainNumbers — replaces the json.Number values of a decoded document with int64, uint64 or float64.
+func plainNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = plainNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = plainNumbers(value)
+		}
+	}
+
+	return v
+}
+
+// isPerCommandError — reports whether a pipeline error is a reply error of an individual command
+// (redis.Nil, WRONGTYPE, ...) rather than a connection failure affecting the whole batch.
+func isPerCommandError(err error) bool {
+	var redisErr rdb.Error
+	return errors.As(err, &redisErr)
+}
@@ -0,0 +1,163 @@
+package earedis
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// base64Codec — JSON wrapped in base64, a second codec to migrate to.
+type base64Codec struct{}
+
+func (base64Codec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func (base64Codec) Unmarshal(data []byte, v interface{}) error {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, v)
+}
+
+func TestMigrateCodec(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	want := map[string]interface{}{
+		"doc:1": map[string]interface{}{"name": "a", "n": 1.0},
+		"doc:2": []interface{}{"x", "y"},
+		"doc:3": "plain",
+	}
+	for key, value := range want {
+		data, err := JSONCodec{}.Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+
+		if err := s.Set(ctx, key, data, time.Hour); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	if err := s.Set(ctx, "other", `"untouched"`, 0); err != nil {
+		t.Fatalf("Set other: %v", err)
+	}
+
+	migrated, err := s.MigrateCodec(ctx, "doc:*", JSONCodec{}, base64Codec{})
+	if err != nil {
+		t.Fatalf("MigrateCodec: %v", err)
+	}
+
+	if migrated != len(want) {
+		t.Fatalf("migrated %d keys, want %d", migrated, len(want))
+	}
+
+	for key, value := range want {
+		data, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get %s: %v", key, err)
+		}
+
+		var got interface{}
+		if err := (base64Codec{}).Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("decode %s with the new codec: %v", key, err)
+		}
+
+		if !reflect.DeepEqual(got, value) {
+			t.Fatalf("%s = %v, want %v", key, got, value)
+		}
+
+		if ttl := s.client.TTL(ctx.GetContext(), key).Val(); ttl <= 0 {
+			t.Fatalf("%s lost its TTL: %v", key, ttl)
+		}
+	}
+
+	if got, _ := s.Get(ctx, "other"); got != `"untouched"` {
+		t.Fatalf("other = %q, migrated outside the pattern", got)
+	}
+}
+
+func TestMigrateCodecHonorsMaxPipelineSize(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.MaxPipelineSize = 2
+	})
+	ctx := testContext(t)
+
+	for i := 0; i < 5; i++ {
+		if err := s.Set(ctx, fmt.Sprint("doc:", i), fmt.Sprintf(`{"n":%d}`, i), 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	hook := &faultHook{}
+	s.client.AddHook(hook)
+
+	migrated, err := s.MigrateCodec(ctx, "doc:*", JSONCodec{}, base64Codec{})
+	if err != nil || migrated != 5 {
+		t.Fatalf("MigrateCodec = %d, %v; want 5", migrated, err)
+	}
+
+	sent := 0
+	for _, batch := range hook.sent {
+		if len(batch) > 2 {
+			t.Fatalf("pipeline of %d commands sent, want at most 2: %v", len(batch), batch)
+		}
+		sent += len(batch)
+	}
+
+	if sent != 10 {
+		t.Fatalf("sent %d commands, want a GET and a SET per key", sent)
+	}
+}
+
+func TestMigrateCodecKeepsLargeIntegers(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	// 2^53 + 1, which float64 rounds to 2^53.
+	const doc = `{"id":9007199254740993,"ids":[18446744073709551615],"ratio":0.5}`
+	if err := s.Set(ctx, "doc:1", doc, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if migrated, err := s.MigrateCodec(ctx, "doc:*", JSONCodec{}, base64Codec{}); err != nil || migrated != 1 {
+		t.Fatalf("MigrateCodec = %d, %v; want 1", migrated, err)
+	}
+
+	data, err := s.Get(ctx, "doc:1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || string(decoded) != doc {
+		t.Fatalf("migrated doc = %s, %v; want %s", decoded, err, doc)
+	}
+}
+
+func TestDecodeGenericToJSONKeepsNumbers(t *testing.T) {
+	v, err := decodeGeneric([]byte(`{"id":123456789012345678901234567890}`), JSONCodec{}, JSONCodec{})
+	if err != nil {
+		t.Fatalf("decodeGeneric: %v", err)
+	}
+
+	encoded, err := JSONCodec{}.Marshal(v)
+	if err != nil || string(encoded) != `{"id":123456789012345678901234567890}` {
+		t.Fatalf("re-encoded = %s, %v; want the number unchanged", encoded, err)
+	}
+
+	if _, err := decodeGeneric([]byte(`{} trailing`), JSONCodec{}, JSONCodec{}); err == nil {
+		t.Fatal("decodeGeneric accepted trailing data")
+	}
+}
@@ -0,0 +1,112 @@
+package earedis
+
+import (
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"time"
+)
+
+const cohortKeyPrefix = "earedis:cohort:"
+
+// cohortKey — the set recording the keys of a cohort.
+func cohortKey(cohort string) string {
+	return cohortKeyPrefix + cohort
+}
+
+// SetInCohort — sets key to value with ttl (0 keeps it forever) and records it in the cohort,
+// so that the whole cohort can later be expired with ExpireCohort.
+func (s *Service) SetInCohort(ctx *eactx.Context, cohort, key string, value interface{}, ttl time.Duration) (err error) {
+	defer s.reportReturned("SetInCohort", key, &err)
+
+	if err := s.checkCommand("SET"); err != nil {
+		return err
+	}
+
+	if err := s.checkCommand("SADD"); err != nil {
+		return err
+	}
+
+	if err := s.checkValueSize(key, value); err != nil {
+		return err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The cohort set lives in the tenant namespace like every other key.
+	key, setKey := tenant+key, tenant+cohortKey(cohort)
+
+	_, err = s.client.Pipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		pipe.Set(ctx.GetContext(), key, value, ttl)
+		pipe.SAdd(ctx.GetContext(), setKey, key)
+		return nil
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to set key in cohort", cohort, key, err)
+		return err
+	}
+
+	return nil
+}
+
+// ExpireCohort — sets ttl on every key recorded in the cohort and on the cohort itself, returning how
+// many keys were still present. Keys that have already vanished are removed from the cohort.
+func (s *Service) ExpireCohort(ctx *eactx.Context, cohort string, ttl time.Duration) (count int, err error) {
+	defer s.reportReturned("ExpireCohort", "", &err)
+
+	if err := s.checkCommand("EXPIRE"); err != nil {
+		return 0, err
+	}
+
+	setKey, err := s.tenantKey(ctx, cohortKey(cohort))
+	if err != nil {
+		return 0, err
+	}
+
+	keys, err := s.client.SMembers(ctx.GetContext(), setKey).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get cohort members", cohort, err)
+		return 0, err
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	expires := make([]*rdb.BoolCmd, len(keys))
+	err = s.pipelined(ctx.GetContext(), s.client, len(keys), func(pipe rdb.Pipeliner, i int) {
+		expires[i] = pipe.Expire(ctx.GetContext(), keys[i], ttl)
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to expire cohort", cohort, err)
+		return 0, err
+	}
+
+	expired := 0
+	vanished := make([]interface{}, 0)
+	for i, cmd := range expires {
+		if cmd.Val() {
+			expired++
+		} else {
+			vanished = append(vanished, keys[i])
+		}
+	}
+
+	_, err = s.client.Pipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		if len(vanished) > 0 {
+			pipe.SRem(ctx.GetContext(), setKey, vanished...)
+		}
+
+		pipe.Expire(ctx.GetContext(), setKey, ttl)
+		return nil
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to clean up cohort", cohort, err)
+		return expired, err
+	}
+
+	s.l.InfoT(s.traceName, "Expired cohort", cohort, expired)
+	return expired, nil
+}
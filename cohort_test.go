@@ -0,0 +1,69 @@
+package earedis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpireCohort(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	for _, key := range []string{"session:1", "session:2", "session:3"} {
+		if err := s.SetInCohort(ctx, "user:7", key, "data", 0); err != nil {
+			t.Fatalf("SetInCohort %s: %v", key, err)
+		}
+	}
+
+	if _, err := s.Del(ctx, "session:2"); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	expired, err := s.ExpireCohort(ctx, "user:7", time.Hour)
+	if err != nil {
+		t.Fatalf("ExpireCohort: %v", err)
+	}
+
+	if expired != 2 {
+		t.Fatalf("ExpireCohort = %d, want 2", expired)
+	}
+
+	for _, key := range []string{"session:1", "session:3", cohortKey("user:7")} {
+		if ttl := s.client.TTL(ctx.GetContext(), key).Val(); ttl <= 0 || ttl > time.Hour {
+			t.Fatalf("TTL of %s = %v, want up to an hour", key, ttl)
+		}
+	}
+
+	members, err := s.SMembers(ctx, cohortKey("user:7"))
+	if err != nil {
+		t.Fatalf("SMembers: %v", err)
+	}
+
+	if len(members) != 2 {
+		t.Fatalf("cohort members %v, want the vanished key removed", members)
+	}
+}
+
+func TestCohortInTenantNamespace(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.TenantExtractor = tenantExtractor
+	})
+	ctx := tenantContext(t, "a")
+
+	if err := s.SetInCohort(ctx, "user:7", "session:1", "data", 0); err != nil {
+		t.Fatalf("SetInCohort: %v", err)
+	}
+
+	members, err := s.client.SMembers(ctx.GetContext(), "a:"+cohortKey("user:7")).Result()
+	if err != nil || len(members) != 1 || members[0] != "a:session:1" {
+		t.Fatalf("raw cohort set = %v, %v; want [a:session:1] under the tenant prefix", members, err)
+	}
+
+	if expired, err := s.ExpireCohort(ctx, "user:7", time.Hour); err != nil || expired != 1 {
+		t.Fatalf("ExpireCohort = %d, %v; want 1", expired, err)
+	}
+
+	if expired, err := s.ExpireCohort(tenantContext(t, "b"), "user:7", time.Hour); err != nil || expired != 0 {
+		t.Fatalf("ExpireCohort of another tenant = %d, %v; want 0", expired, err)
+	}
+}
@@ -0,0 +1,86 @@
+package earedis
+
+import (
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"math"
+	"strconv"
+)
+
+// setIfScript — stores ARGV[1] when the key is missing or when ARGV[1] compares to the current value as ARGV[2] ("gt" or "lt").
+// Returns {updated, resulting value}. The script SHA is cached by rdb.Script, which falls back to EVAL on NOSCRIPT.
+var setIfScript = rdb.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current == false then
+	redis.call('SET', KEYS[1], ARGV[1])
+	return {1, ARGV[1]}
+end
+
+local value, stored = tonumber(ARGV[1]), tonumber(current)
+if stored == nil then
+	return redis.error_reply('ERR value is not a valid float')
+end
+
+if (ARGV[2] == 'gt' and value > stored) or (ARGV[2] == 'lt' and value < stored) then
+	redis.call('SET', KEYS[1], ARGV[1], 'KEEPTTL')
+	return {1, ARGV[1]}
+end
+
+return {0, current}
+`)
+
+// SetIfGreater — atomically sets key to value only if the key is missing or value is greater than the stored number.
+// Returns whether the key was updated and the value stored afterwards. The TTL of an existing key is kept.
+// NaN and infinite values are rejected.
+func (s *Service) SetIfGreater(ctx *eactx.Context, key string, value float64) (updated bool, current float64, err error) {
+	defer s.reportReturned("SetIfGreater", key, &err)
+
+	return s.setIf(ctx, key, value, "gt")
+}
+
+// SetIfLess — the SetIfGreater twin, updating only if value is less than the stored number.
+func (s *Service) SetIfLess(ctx *eactx.Context, key string, value float64) (updated bool, current float64, err error) {
+	defer s.reportReturned("SetIfLess", key, &err)
+
+	return s.setIf(ctx, key, value, "lt")
+}
+
+func (s *Service) setIf(ctx *eactx.Context, key string, value float64, op string) (bool, float64, error) {
+	if err := s.checkCommand("EVAL"); err != nil {
+		return false, 0, err
+	}
+
+	// NaN and infinities format to strings Lua's tonumber cannot compare.
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return false, 0, fmt.Errorf("earedis: %v is not a finite number", value)
+	}
+
+	key, err := s.tenantKey(ctx, key)
+	if err != nil {
+		return false, 0, err
+	}
+
+	arg := strconv.FormatFloat(value, 'f', -1, 64)
+
+	result, err := setIfScript.Run(ctx.GetContext(), s.client, []string{key}, arg, op).Slice()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to compare and set key", key, err)
+		return false, 0, err
+	}
+
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("earedis: unexpected script reply %v", result)
+	}
+
+	updated, _ := result[0].(int64)
+	stored, _ := result[1].(string)
+
+	current, err := strconv.ParseFloat(stored, 64)
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to parse value at key", key, err)
+		return false, 0, err
+	}
+
+	return updated == 1, current, nil
+}
@@ -0,0 +1,71 @@
+package earedis
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestSetIfGreaterRacingWriters(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	const writers = 50
+
+	var wg sync.WaitGroup
+	for _, value := range rand.Perm(writers) {
+		wg.Add(1)
+		go func(value float64) {
+			defer wg.Done()
+
+			if _, _, err := s.SetIfGreater(ctx, "max", value); err != nil {
+				t.Errorf("SetIfGreater(%v): %v", value, err)
+			}
+		}(float64(value))
+	}
+	wg.Wait()
+
+	updated, current, err := s.SetIfGreater(ctx, "max", 0)
+	if err != nil {
+		t.Fatalf("SetIfGreater: %v", err)
+	}
+
+	if updated || current != writers-1 {
+		t.Fatalf("SetIfGreater(0) = %v, %v; want false, %d", updated, current, writers-1)
+	}
+}
+
+func TestSetIfLess(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	for _, step := range []struct {
+		value   float64
+		updated bool
+		current float64
+	}{
+		{5, true, 5},
+		{7, false, 5},
+		{2.5, true, 2.5},
+	} {
+		updated, current, err := s.SetIfLess(ctx, "min", step.value)
+		if err != nil {
+			t.Fatalf("SetIfLess(%v): %v", step.value, err)
+		}
+
+		if updated != step.updated || current != step.current {
+			t.Fatalf("SetIfLess(%v) = %v, %v; want %v, %v", step.value, updated, current, step.updated, step.current)
+		}
+	}
+}
+
+func TestSetIfGreaterRejectsNaN(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, _, err := s.SetIfGreater(testContext(t), "max", value); err == nil {
+			t.Fatalf("SetIfGreater(%v) succeeded", value)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package earedis
+
+import "github.com/eris-apple/eactx"
+
+// ConfigGet — returns the server configuration parameters matching parameter (a glob, e.g. "maxmemory*").
+func (s *Service) ConfigGet(ctx *eactx.Context, parameter string) (values map[string]string, err error) {
+	defer s.reportReturned("ConfigGet", "", &err)
+
+	if err := s.checkCommand("CONFIG GET"); err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.ConfigGet(ctx.GetContext(), parameter).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get config", parameter, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ConfigSet — changes a server configuration parameter at runtime.
+// Refused with ErrCommandDisabled unless ConnectConfig.AllowAdminCommands is set.
+func (s *Service) ConfigSet(ctx *eactx.Context, parameter, value string) (err error) {
+	defer s.reportReturned("ConfigSet", "", &err)
+
+	if err := s.checkCommand("CONFIG SET"); err != nil {
+		return err
+	}
+
+	if err := s.client.ConfigSet(ctx.GetContext(), parameter, value).Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to set config", parameter, value, err)
+		return err
+	}
+
+	s.l.WarnT(s.traceName, "Changed server config", parameter, "=", value)
+	return nil
+}
@@ -0,0 +1,44 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfigSetMaxmemorySamples(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.AllowAdminCommands = true
+	})
+	ctx := testContext(t)
+
+	before, err := s.ConfigGet(ctx, "maxmemory-samples")
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("ConfigGet: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = s.ConfigSet(testContext(t), "maxmemory-samples", before["maxmemory-samples"])
+	})
+
+	if err := s.ConfigSet(ctx, "maxmemory-samples", "7"); err != nil {
+		t.Fatalf("ConfigSet: %v", err)
+	}
+
+	after, err := s.ConfigGet(ctx, "maxmemory-samples")
+	if err != nil {
+		t.Fatalf("ConfigGet: %v", err)
+	}
+
+	if after["maxmemory-samples"] != "7" {
+		t.Fatalf("maxmemory-samples = %q, want 7", after["maxmemory-samples"])
+	}
+}
+
+func TestConfigSetDeniedByDefault(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+
+	if err := s.ConfigSet(testContext(t), "maxmemory-samples", "7"); !errors.Is(err, ErrCommandDisabled) {
+		t.Fatalf("ConfigSet error = %v, want ErrCommandDisabled", err)
+	}
+}
@@ -0,0 +1,129 @@
+package earedis
+
+import (
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+)
+
+// WaitAOF — blocks until the writes made so far on the connection running WAITAOF are fsynced to the AOF of
+// at least numLocal local servers (0 or 1) and numReplicas replicas, or until timeout (0 waits forever).
+// Returns how many local servers and replicas acknowledged. Requires Redis 7.2+; returns ErrAOFDisabled
+// when numLocal is set but the server runs without appendonly. The connection comes from the pool (in cluster
+// mode from an arbitrary node), so it may not be the one a preceding write went through: to confirm a specific
+// write use SetAndWaitAOF, which sends the write and WAITAOF on one connection.
+func (s *Service) WaitAOF(ctx *eactx.Context, numLocal, numReplicas int, timeout time.Duration) (local int64, replicas int64, err error) {
+	defer s.reportReturned("WaitAOF", "", &err)
+
+	if err := s.checkCommand("WAITAOF"); err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.requireVersion("WAITAOF", 7, 2); err != nil {
+		return 0, 0, err
+	}
+
+	local, replicas, err = waitAOFReply(s.client.Do(ctx.GetContext(), "waitaof", numLocal, numReplicas, timeout.Milliseconds()))
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to wait for AOF", err)
+		return 0, 0, err
+	}
+
+	return local, replicas, nil
+}
+
+// SetAndWaitAOF — Set followed by WaitAOF for that write: SET and WAITAOF are sent in one pipeline on the
+// connection of the node owning key, so the wait covers exactly this write. Returns the acknowledgements like
+// WaitAOF; the value is stored even when the wait fails or times out.
+func (s *Service) SetAndWaitAOF(ctx *eactx.Context, key string, value interface{}, expiration time.Duration, numLocal, numReplicas int, timeout time.Duration) (local int64, replicas int64, err error) {
+	defer s.reportReturned("SetAndWaitAOF", key, &err)
+
+	if err := s.checkCommand("SET"); err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.checkCommand("WAITAOF"); err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.requireVersion("WAITAOF", 7, 2); err != nil {
+		return 0, 0, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.checkValueSize(key, value); err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.checkKey(key); err != nil {
+		return 0, 0, err
+	}
+
+	node, err := s.keyNode(ctx, key)
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to find node for AOF wait", key, err)
+		return 0, 0, err
+	}
+
+	pipe := node.Pipeline()
+	set := pipe.Set(ctx.GetContext(), key, value, expiration)
+	wait := pipe.Do(ctx.GetContext(), "waitaof", numLocal, numReplicas, timeout.Milliseconds())
+
+	_, err = pipe.Exec(ctx.GetContext())
+	if err != nil && !isPerCommandError(err) {
+		s.recordKey(key, err)
+		s.l.ErrorT(s.traceName, "Failed to set key and wait for AOF", key, err)
+		return 0, 0, err
+	}
+
+	s.recordKey(key, set.Err())
+	if err := set.Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to set key", key, err)
+		return 0, 0, err
+	}
+
+	s.publishInvalidation(ctx, key)
+
+	local, replicas, err = waitAOFReply(wait)
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to wait for AOF", key, err)
+		return 0, 0, err
+	}
+
+	return local, replicas, nil
+}
+
+// waitAOFReply — parses the [local, replicas] reply of WAITAOF, wrapping the appendonly error in ErrAOFDisabled.
+func waitAOFReply(wait *rdb.Cmd) (int64, int64, error) {
+	result, err := wait.Int64Slice()
+	if err != nil {
+		if strings.Contains(err.Error(), "appendonly is disabled") {
+			err = fmt.Errorf("%w: %s", ErrAOFDisabled, err)
+		}
+
+		return 0, 0, err
+	}
+
+	if len(result) != 2 {
+		return 0, 0, fmt.Errorf("earedis: unexpected WAITAOF reply %v", result)
+	}
+
+	return result[0], result[1], nil
+}
+
+// keyNode — returns the client of the node serving key: the service client in standalone mode,
+// the master owning key in cluster mode.
+func (s *Service) keyNode(ctx *eactx.Context, key string) (rdb.Cmdable, error) {
+	cluster, ok := s.client.(*rdb.ClusterClient)
+	if !ok {
+		return s.client, nil
+	}
+
+	return cluster.MasterForKey(ctx.GetContext(), key)
+}
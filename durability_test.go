@@ -0,0 +1,83 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// appendOnly — reports whether the test server runs with appendonly enabled.
+func appendOnly(t *testing.T, s *Service) bool {
+	t.Helper()
+
+	config, err := s.client.ConfigGet(testContext(t).GetContext(), "appendonly").Result()
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("CONFIG GET appendonly: %v", err)
+	}
+
+	return config["appendonly"] == "yes"
+}
+
+func TestWaitAOF(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if !appendOnly(t, s) {
+		t.Skip("the test server runs without appendonly")
+	}
+
+	local, _, err := s.SetAndWaitAOF(ctx, "durable", "value", 0, 1, 0, 5*time.Second)
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("SetAndWaitAOF: %v", err)
+	}
+
+	if local != 1 {
+		t.Fatalf("SetAndWaitAOF local acks = %d, want 1", local)
+	}
+
+	if got, err := s.Get(ctx, "durable"); err != nil || got != "value" {
+		t.Fatalf("Get = %q, %v; want \"value\"", got, err)
+	}
+
+	if local, _, err := s.WaitAOF(ctx, 1, 0, 5*time.Second); err != nil || local != 1 {
+		t.Fatalf("WaitAOF = %d, %v; want 1 local ack", local, err)
+	}
+}
+
+func TestWaitAOFDisabled(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if appendOnly(t, s) {
+		t.Skip("the test server runs with appendonly")
+	}
+
+	_, _, err := s.WaitAOF(ctx, 1, 0, time.Second)
+	skipUnsupported(t, err)
+	if !errors.Is(err, ErrAOFDisabled) {
+		t.Fatalf("WaitAOF error = %v, want ErrAOFDisabled", err)
+	}
+
+	// The value is stored even though the wait fails.
+	if _, _, err := s.SetAndWaitAOF(ctx, "durable", "value", 0, 1, 0, time.Second); !errors.Is(err, ErrAOFDisabled) {
+		t.Fatalf("SetAndWaitAOF error = %v, want ErrAOFDisabled", err)
+	}
+
+	if got, err := s.Get(ctx, "durable"); err != nil || got != "value" {
+		t.Fatalf("Get = %q, %v; want \"value\"", got, err)
+	}
+}
+
+func TestSetAndWaitAOFGuards(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{MaxValueSize: 4, TenantExtractor: tenantExtractor}, "test")
+
+	if _, _, err := s.SetAndWaitAOF(tenantContext(t, "a"), "durable", "too large", 0, 1, 0, time.Second); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("SetAndWaitAOF of an oversized value: %v, want ErrValueTooLarge", err)
+	}
+
+	if _, _, err := s.SetAndWaitAOF(testContext(t), "durable", "v", 0, 1, 0, time.Second); !errors.Is(err, ErrNoTenant) {
+		t.Fatalf("SetAndWaitAOF without tenant: %v, want ErrNoTenant", err)
+	}
+}
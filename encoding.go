@@ -0,0 +1,36 @@
+package earedis
+
+import (
+	"github.com/eris-apple/eactx"
+	"math/rand/v2"
+)
+
+// compactEncodings — the memory-efficient encodings of small sets, hashes and sorted sets.
+var compactEncodings = map[string]struct{}{
+	"listpack": {},
+	"ziplist":  {},
+	"intset":   {},
+}
+
+// adviseEncoding — on a sample of ConnectConfig.EncodingAdvisoryRate of the writes to a collection,
+// checks OBJECT ENCODING and warns when the collection has left its compact encoding.
+// The advice is skipped when OBJECT ENCODING is refused; failures of the check itself are only logged at debug level.
+func (s *Service) adviseEncoding(ctx *eactx.Context, key string) {
+	if s.c.EncodingAdvisoryRate <= 0 || rand.Float64() >= s.c.EncodingAdvisoryRate {
+		return
+	}
+
+	if err := s.checkCommand("OBJECT ENCODING"); err != nil {
+		return
+	}
+
+	encoding, err := s.client.ObjectEncoding(ctx.GetContext(), key).Result()
+	if err != nil {
+		s.l.DebugT(s.traceName, "Failed to sample encoding of key", key, err)
+		return
+	}
+
+	if _, ok := compactEncodings[encoding]; !ok {
+		s.l.WarnT(s.traceName, "Collection left its compact encoding", key, encoding)
+	}
+}
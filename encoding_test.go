@@ -0,0 +1,77 @@
+package earedis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAdviseEncodingWarnsOnTransition(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.EncodingAdvisoryRate = 1
+	})
+	ctx := testContext(t)
+
+	logger, logs := captureLogger(t)
+	s.l = logger
+
+	if err := s.SAdd(ctx, "members", "a", "b"); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+
+	encoding, err := s.client.ObjectEncoding(ctx.GetContext(), "members").Result()
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("OBJECT ENCODING: %v", err)
+	}
+
+	if _, ok := compactEncodings[encoding]; !ok {
+		t.Skipf("the server stores small sets as %s", encoding)
+	}
+
+	// More members than set-max-listpack-entries (and set-max-intset-entries) allow.
+	members := make([]interface{}, 1000)
+	for i := range members {
+		members[i] = fmt.Sprint("member-", i)
+	}
+
+	if err := s.SAdd(ctx, "members", members...); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+
+	out := logs()
+	if strings.Count(out, "Collection left its compact encoding") != 1 || !strings.Contains(out, "members hashtable") {
+		t.Fatalf("want one advisory for members, logged:\n%s", out)
+	}
+}
+
+func TestAdviseEncodingDisabledByDefault(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	logger, logs := captureLogger(t)
+	s.l = logger
+
+	members := make([]interface{}, 1000)
+	for i := range members {
+		members[i] = fmt.Sprint("member-", i)
+	}
+
+	if err := s.SAdd(ctx, "members", members...); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+
+	if out := logs(); strings.Contains(out, "Collection left its compact encoding") {
+		t.Fatalf("advisory logged while disabled:\n%s", out)
+	}
+}
+
+func TestAdviseEncodingSkippedWhenDisallowed(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{
+		EncodingAdvisoryRate: 1,
+		DisallowedCommands:   []string{"OBJECT ENCODING"},
+	}, "test")
+
+	// Without a client, anything but skipping the advice would panic.
+	s.adviseEncoding(testContext(t), "members")
+}
@@ -0,0 +1,103 @@
+package earedis
+
+import (
+	"errors"
+	"github.com/eris-apple/ealogger"
+	rdb "github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
+)
+
+// errorReportBuffer — error reports queued for ConnectConfig.OnError before further ones are dropped.
+const errorReportBuffer = 1024
+
+// errorReport — one call of ConnectConfig.OnError.
+type errorReport struct {
+	method string
+	key    string
+	err    error
+}
+
+// errorReporter — runs ConnectConfig.OnError on a single worker fed by a bounded queue, so a slow callback
+// never stalls the command path and an outage cannot pile up goroutines. Reports arriving while the queue
+// is full are dropped and counted.
+type errorReporter struct {
+	onError func(method, key string, err error)
+	reports chan errorReport
+	done    chan struct{}
+	stop    sync.Once
+
+	dropped atomic.Int64
+
+	l         *ealogger.Logger
+	traceName string
+}
+
+func newErrorReporter(onError func(method, key string, err error), l *ealogger.Logger, traceName string) *errorReporter {
+	r := &errorReporter{
+		onError: onError,
+		reports: make(chan errorReport, errorReportBuffer),
+		done:    make(chan struct{}),
+
+		l:         l,
+		traceName: traceName,
+	}
+
+	go r.run()
+	return r
+}
+
+// run — hands queued reports to the callback until close.
+func (r *errorReporter) run() {
+	var warned int64
+	for {
+		select {
+		case <-r.done:
+			return
+		case report := <-r.reports:
+			if dropped := r.dropped.Load(); dropped > warned {
+				r.l.WarnT(r.traceName, "Dropped error reports, OnError is falling behind", dropped-warned)
+				warned = dropped
+			}
+
+			r.onError(report.method, report.key, report.err)
+		}
+	}
+}
+
+// report — queues a report, dropping it when the queue is full.
+func (r *errorReporter) report(report errorReport) {
+	select {
+	case r.reports <- report:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// close — stops the worker; reports still queued are discarded.
+func (r *errorReporter) close() {
+	r.stop.Do(func() {
+		close(r.done)
+	})
+}
+
+// reportReturned — hands the error a method is returning to ConnectConfig.OnError. Every exported method
+// defers it first thing with its name and key ("" for methods on several keys or none), so errors the method
+// handles itself are never reported. Misses (ErrNotFound, redis.Nil) are not reported.
+func (s *Service) reportReturned(method, key string, err *error) {
+	if s.reporter == nil || *err == nil || errors.Is(*err, ErrNotFound) || errors.Is(*err, rdb.Nil) {
+		return
+	}
+
+	s.reporter.report(errorReport{method: method, key: key, err: *err})
+}
+
+// DroppedErrorReports — returns how many errors were not handed to ConnectConfig.OnError because
+// the callback fell more than 1024 reports behind.
+func (s *Service) DroppedErrorReports() int64 {
+	if s.reporter == nil {
+		return 0
+	}
+
+	return s.reporter.dropped.Load()
+}
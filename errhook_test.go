@@ -0,0 +1,259 @@
+package earedis
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collectErrors — sets ConnectConfig.OnError to send every report to the returned channel.
+func collectErrors(c *ConnectConfig) <-chan errorReport {
+	reports := make(chan errorReport, 64)
+	c.OnError = func(method, key string, err error) {
+		reports <- errorReport{method: method, key: key, err: err}
+	}
+
+	return reports
+}
+
+// nextReport — waits for the next OnError call.
+func nextReport(t *testing.T, reports <-chan errorReport) errorReport {
+	t.Helper()
+
+	select {
+	case report := <-reports:
+		return report
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnError was not called")
+		return errorReport{}
+	}
+}
+
+// noReport — fails if OnError is called within a short grace period.
+func noReport(t *testing.T, reports <-chan errorReport) {
+	t.Helper()
+
+	select {
+	case report := <-reports:
+		t.Fatalf("unexpected OnError(%q, %q, %v)", report.method, report.key, report.err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestOnErrorReportsFailures(t *testing.T) {
+	var reports <-chan errorReport
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.MaxValueSize = 4
+		reports = collectErrors(c)
+	})
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "str", "abc", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := s.SAdd(ctx, "str", "m"); err == nil {
+		t.Fatal("SAdd on a string succeeded")
+	}
+
+	if report := nextReport(t, reports); report.method != "SAdd" || report.key != "str" || !strings.Contains(report.err.Error(), "WRONGTYPE") {
+		t.Fatalf("OnError(%q, %q, %v), want SAdd on str with WRONGTYPE", report.method, report.key, report.err)
+	}
+
+	if err := s.Set(ctx, "big", "12345", 0); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Set of an oversized value: %v, want ErrValueTooLarge", err)
+	}
+
+	if report := nextReport(t, reports); report.method != "Set" || report.key != "big" || !errors.Is(report.err, ErrValueTooLarge) {
+		t.Fatalf("OnError(%q, %q, %v), want Set on big with ErrValueTooLarge", report.method, report.key, report.err)
+	}
+
+	var v map[string]int
+	if err := s.JSONGet(ctx, "str", v); !errors.Is(err, ErrInvalidTarget) {
+		t.Fatalf("JSONGet into a map value: %v, want ErrInvalidTarget", err)
+	}
+
+	if report := nextReport(t, reports); report.method != "JSONGet" || report.key != "str" || !errors.Is(report.err, ErrInvalidTarget) {
+		t.Fatalf("OnError(%q, %q, %v), want JSONGet on str with ErrInvalidTarget", report.method, report.key, report.err)
+	}
+
+	if err := s.JSONGet(ctx, "str", &v); err == nil {
+		t.Fatal("JSONGet of a non-JSON value succeeded")
+	}
+
+	if report := nextReport(t, reports); report.method != "JSONGet" || report.key != "str" || report.err == nil {
+		t.Fatalf("OnError(%q, %q, %v), want JSONGet on str with a decode error", report.method, report.key, report.err)
+	}
+
+	if err := s.client.HSet(ctx.GetContext(), "map", `"k"`, "oops").Err(); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	if _, _, err := NewMap[string, int](s, "map").Get(ctx, "k"); err == nil {
+		t.Fatal("Map.Get of a non-JSON value succeeded")
+	}
+
+	if report := nextReport(t, reports); report.method != "Map.Get" || report.key != "map" || report.err == nil {
+		t.Fatalf("OnError(%q, %q, %v), want Map.Get on map with a decode error", report.method, report.key, report.err)
+	}
+
+	if _, err := s.Get(ctx, "missing"); err == nil {
+		t.Fatal("Get of a missing key succeeded")
+	}
+
+	noReport(t, reports)
+}
+
+func TestOnErrorReportsMissingTenant(t *testing.T) {
+	var reports <-chan errorReport
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.TenantExtractor = tenantExtractor
+		reports = collectErrors(c)
+	})
+
+	if _, err := s.Get(testContext(t), "key"); !errors.Is(err, ErrNoTenant) {
+		t.Fatalf("Get without tenant: %v, want ErrNoTenant", err)
+	}
+
+	if report := nextReport(t, reports); report.method != "Get" || report.key != "key" || !errors.Is(report.err, ErrNoTenant) {
+		t.Fatalf("OnError(%q, %q, %v), want Get with ErrNoTenant", report.method, report.key, report.err)
+	}
+}
+
+func TestOnErrorSkipsRetriedConflicts(t *testing.T) {
+	var reports <-chan errorReport
+	s := newTestService(t, func(c *ConnectConfig) {
+		reports = collectErrors(c)
+	})
+	ctx := testContext(t)
+
+	type counter struct {
+		N int `json:"n"`
+	}
+
+	if err := s.Set(ctx, "counter", `{"n":0}`, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	started, proceed := make(chan struct{}), make(chan struct{})
+	attempts := 0
+	done := make(chan error, 1)
+
+	go func() {
+		var c counter
+		done <- s.JSONUpdate(ctx, "counter", &c, func() error {
+			attempts++
+			if attempts == 1 {
+				close(started)
+				<-proceed
+			}
+
+			c.N++
+			return nil
+		})
+	}()
+
+	<-started
+	var c counter
+	if err := s.JSONUpdate(ctx, "counter", &c, func() error { c.N++; return nil }); err != nil {
+		t.Fatalf("JSONUpdate of the second writer: %v", err)
+	}
+	close(proceed)
+
+	if err := <-done; err != nil {
+		t.Fatalf("JSONUpdate of the first writer: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("first writer ran mutate %d times, want 2", attempts)
+	}
+
+	noReport(t, reports)
+}
+
+func TestOnErrorReportsRejectedArguments(t *testing.T) {
+	var reports <-chan errorReport
+	s := newTestService(t, func(c *ConnectConfig) {
+		reports = collectErrors(c)
+	})
+	ctx := testContext(t)
+
+	if _, err := s.PushCapped(ctx, "list", "v", -1); err == nil {
+		t.Fatal("PushCapped with a negative cap succeeded")
+	}
+
+	if report := nextReport(t, reports); report.method != "PushCapped" || report.key != "list" || report.err == nil {
+		t.Fatalf("OnError(%q, %q, %v), want PushCapped on list", report.method, report.key, report.err)
+	}
+
+	if _, err := s.WaitForKey(ctx, "never", 10*time.Millisecond, 50*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("WaitForKey of a missing key: %v, want ErrTimeout", err)
+	}
+
+	if report := nextReport(t, reports); report.method != "WaitForKey" || report.key != "never" || !errors.Is(report.err, ErrTimeout) {
+		t.Fatalf("OnError(%q, %q, %v), want WaitForKey on never with ErrTimeout", report.method, report.key, report.err)
+	}
+}
+
+func TestOnErrorSkipsHandledErrors(t *testing.T) {
+	var reports <-chan errorReport
+	s := newTestService(t, func(c *ConnectConfig) {
+		reports = collectErrors(c)
+	})
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "child", "v", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := s.SAdd(ctx, "parent", "child", "hash"); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+
+	if err := s.client.HSet(ctx.GetContext(), "hash", "f", "v").Err(); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	// The member holding a hash fails with WRONGTYPE and is skipped.
+	if got, err := s.SMembersWithChild(ctx, "parent"); err != nil || len(got) != 1 {
+		t.Fatalf("SMembersWithChild = %v, %v; want the one string member", got, err)
+	}
+
+	// A keyspace notification probe refused by the server falls back to polling.
+	if got, err := s.WaitForKey(ctx, "child", 10*time.Millisecond, time.Second); err != nil || got != "v" {
+		t.Fatalf("WaitForKey = %q, %v; want v", got, err)
+	}
+
+	noReport(t, reports)
+}
+
+func TestErrorReporterCountsDrops(t *testing.T) {
+	l, logs := captureLogger(t)
+
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	r := newErrorReporter(func(method, key string, err error) {
+		started <- struct{}{}
+		<-release
+	}, l, "test")
+	defer r.close()
+
+	r.report(errorReport{method: "Get", err: errors.New("first")})
+	<-started
+
+	for i := 0; i < errorReportBuffer+3; i++ {
+		r.report(errorReport{method: "Get", err: errors.New("queued")})
+	}
+
+	if got := r.dropped.Load(); got != 3 {
+		t.Fatalf("dropped %d reports, want 3", got)
+	}
+
+	release <- struct{}{}
+	<-started
+	close(release)
+
+	if out := logs(); !strings.Contains(out, "Dropped error reports") {
+		t.Fatalf("no warning about the dropped reports in %q", out)
+	}
+}
@@ -0,0 +1,66 @@
+package earedis
+
+import "errors"
+
+var (
+	// ErrNotFound — the requested key does not exist or holds no data.
+	ErrNotFound = errors.New("earedis: not found")
+
+	// ErrUnknownFormat — the requested export format is not supported.
+	ErrUnknownFormat = errors.New("earedis: unknown format")
+
+	// ErrNotClusterMode — the operation requires a service connected in cluster mode.
+	ErrNotClusterMode = errors.New("earedis: not in cluster mode")
+
+	// ErrCommandDisabled — the command is listed in ConnectConfig.DisallowedCommands.
+	ErrCommandDisabled = errors.New("earedis: command disabled")
+
+	// ErrAOFDisabled — the server has no append-only file to confirm durability against.
+	ErrAOFDisabled = errors.New("earedis: AOF is disabled")
+
+	// ErrPoolExhausted — every slot of the pool is currently leased.
+	ErrPoolExhausted = errors.New("earedis: pool exhausted")
+
+	// ErrLeaseNotHeld — the lease expired or belongs to another holder.
+	ErrLeaseNotHeld = errors.New("earedis: lease not held")
+
+	// ErrTimeout — the awaited condition did not occur in time.
+	ErrTimeout = errors.New("earedis: timeout")
+
+	// ErrValueTooLarge — the value exceeds ConnectConfig.MaxValueSize.
+	ErrValueTooLarge = errors.New("earedis: value too large")
+
+	// ErrConflict — an optimistic update kept losing to concurrent writers.
+	ErrConflict = errors.New("earedis: conflict")
+
+	// ErrKeyCircuitOpen — the key failed repeatedly and is short-circuited for ConnectConfig.KeyBreaker.Cooldown.
+	ErrKeyCircuitOpen = errors.New("earedis: key circuit open")
+
+	// ErrNoTenant — ConnectConfig.TenantExtractor found no tenant in the context.
+	ErrNoTenant = errors.New("earedis: no tenant in context")
+
+	// ErrInvalidTenant — the tenant contains ":" or a glob character (*?[]\), which would let its namespace
+	// overlap other tenants' keys or key patterns.
+	ErrInvalidTenant = errors.New("earedis: invalid tenant")
+
+	// ErrNotNamespaced — the operation cannot be namespaced and is refused while ConnectConfig.TenantExtractor is set.
+	ErrNotNamespaced = errors.New("earedis: operation cannot be namespaced by tenant")
+
+	// ErrModuleNotLoaded — the command belongs to a server module (e.g. RedisJSON) that is not loaded.
+	ErrModuleNotLoaded = errors.New("earedis: module not loaded")
+
+	// ErrBackpressure — the subscriber fell behind and its subscription was closed.
+	ErrBackpressure = errors.New("earedis: subscriber too slow")
+
+	// ErrInvalidTarget — a decode target is not a non-nil pointer (or, for slice helpers, a pointer to a slice).
+	ErrInvalidTarget = errors.New("earedis: invalid target")
+
+	// ErrUnsupported — the server is too old for the command.
+	ErrUnsupported = errors.New("earedis: unsupported by server")
+
+	// ErrReadOnlyMode — a write was attempted on a service configured with ConnectConfig.ReadOnly.
+	ErrReadOnlyMode = errors.New("earedis: read-only mode")
+
+	// ErrNotInitialized — the operation needs a connected service, but Init has not been called.
+	ErrNotInitialized = errors.New("earedis: service not initialized")
+)
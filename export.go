@@ -0,0 +1,178 @@
+package earedis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	"io"
+)
+
+const (
+	// ExportCSV — comma-separated rows, preceded by a header row.
+	ExportCSV = "csv"
+	// ExportNDJSON — one JSON object per line.
+	ExportNDJSON = "ndjson"
+
+	exportScanCount = 1000
+)
+
+// exportWriter — streams rows of a collection in the chosen format.
+type exportWriter struct {
+	format string
+	header []string
+
+	csv  *csv.Writer
+	json *json.Encoder
+
+	rows int
+}
+
+func newExportWriter(w io.Writer, format string, header ...string) (*exportWriter, error) {
+	ew := &exportWriter{format: format, header: header}
+
+	switch format {
+	case ExportCSV:
+		ew.csv = csv.NewWriter(w)
+	case ExportNDJSON:
+		ew.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	return ew, nil
+}
+
+func (ew *exportWriter) write(values ...string) error {
+	if ew.csv != nil {
+		if ew.rows == 0 {
+			if err := ew.csv.Write(ew.header); err != nil {
+				return err
+			}
+		}
+		ew.rows++
+		return ew.csv.Write(values)
+	}
+
+	row := make(map[string]string, len(values))
+	for i, v := range values {
+		row[ew.header[i]] = v
+	}
+
+	ew.rows++
+	return ew.json.Encode(row)
+}
+
+func (ew *exportWriter) flush() error {
+	if ew.csv == nil {
+		return nil
+	}
+
+	ew.csv.Flush()
+	return ew.csv.Error()
+}
+
+// ExportHash — streams every field of the hash at key to w as "field,value" rows using HSCAN.
+// HSCAN may return a field more than once if the hash is modified during the export.
+func (s *Service) ExportHash(ctx *eactx.Context, key string, w io.Writer, format string) (err error) {
+	defer s.reportReturned("ExportHash", key, &err)
+
+	if err := s.checkCommand("HSCAN"); err != nil {
+		return err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	ew, err := newExportWriter(w, format, "field", "value")
+	if err != nil {
+		return err
+	}
+
+	var cursor uint64
+	for {
+		var values []string
+		values, cursor, err = s.client.HScan(ctx.GetContext(), key, cursor, "", exportScanCount).Result()
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to scan hash", key, err)
+			return err
+		}
+
+		for i := 0; i+1 < len(values); i += 2 {
+			if err := ew.write(values[i], values[i+1]); err != nil {
+				s.l.ErrorT(s.traceName, "Failed to export hash", key, err)
+				return err
+			}
+		}
+
+		if err := ew.flush(); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to export hash", key, err)
+			return err
+		}
+
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return s.exportResult(ew, key)
+}
+
+// ExportSet — streams every member of the set at key to w as "member" rows using SSCAN.
+// SSCAN may return a member more than once if the set is modified during the export.
+func (s *Service) ExportSet(ctx *eactx.Context, key string, w io.Writer, format string) (err error) {
+	defer s.reportReturned("ExportSet", key, &err)
+
+	if err := s.checkCommand("SSCAN"); err != nil {
+		return err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	ew, err := newExportWriter(w, format, "member")
+	if err != nil {
+		return err
+	}
+
+	var cursor uint64
+	for {
+		var members []string
+		members, cursor, err = s.client.SScan(ctx.GetContext(), key, cursor, "", exportScanCount).Result()
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to scan set", key, err)
+			return err
+		}
+
+		for _, member := range members {
+			if err := ew.write(member); err != nil {
+				s.l.ErrorT(s.traceName, "Failed to export set", key, err)
+				return err
+			}
+		}
+
+		if err := ew.flush(); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to export set", key, err)
+			return err
+		}
+
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return s.exportResult(ew, key)
+}
+
+// exportResult — reports an empty export as ErrNotFound when ConnectConfig.StrictExport is set.
+func (s *Service) exportResult(ew *exportWriter, key string) error {
+	if ew.rows == 0 && s.c.StrictExport {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return nil
+}
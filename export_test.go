@@ -0,0 +1,60 @@
+package earedis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExportHashNDJSON(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	const fields = 3000
+	want := make(map[string]string, fields)
+	values := make([]interface{}, 0, 2*fields)
+	for i := 0; i < fields; i++ {
+		field, value := fmt.Sprintf("field-%d", i), fmt.Sprintf("value-%d", i)
+		want[field] = value
+		values = append(values, field, value)
+	}
+
+	if err := s.client.HSet(ctx.GetContext(), "export:hash", values...).Err(); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.ExportHash(ctx, "export:hash", &buf, ExportNDJSON); err != nil {
+		t.Fatalf("ExportHash: %v", err)
+	}
+
+	got := make(map[string]string, fields)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var row map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("decode row %q: %v", scanner.Text(), err)
+		}
+
+		got[row["field"]] = row["value"]
+	}
+
+	if len(got) != fields {
+		t.Fatalf("exported %d fields, want %d", len(got), fields)
+	}
+
+	for field, value := range want {
+		if got[field] != value {
+			t.Fatalf("field %s = %q, want %q", field, got[field], value)
+		}
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if _, err := newExportWriter(&bytes.Buffer{}, "xml", "field"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("newExportWriter error = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,51 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"time"
+)
+
+// getExtendScript — returns the value of KEYS[1] and sets its TTL to ARGV[1] milliseconds, or nil if the key is missing.
+var getExtendScript = rdb.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+
+return value
+`)
+
+// GetExtendIfPresent — atomically reads key and resets its TTL to ttl, e.g. for sliding rate-limit windows.
+// A missing key is reported with existed false and is not created. ttl must be at least a millisecond,
+// as a shorter one would expire the key on the spot.
+func (s *Service) GetExtendIfPresent(ctx *eactx.Context, key string, ttl time.Duration) (value string, existed bool, err error) {
+	defer s.reportReturned("GetExtendIfPresent", key, &err)
+
+	if err := s.checkCommand("EVAL"); err != nil {
+		return "", false, err
+	}
+
+	if ttl < time.Millisecond {
+		return "", false, fmt.Errorf("earedis: invalid ttl %v", ttl)
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+
+	value, err = getExtendScript.Run(ctx.GetContext(), s.client, []string{key}, ttl.Milliseconds()).Text()
+	if errors.Is(err, rdb.Nil) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get and extend key", key, err)
+		return "", false, err
+	}
+
+	return value, true, nil
+}
@@ -0,0 +1,43 @@
+package earedis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetExtendIfPresent(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "counter", "3", time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	value, existed, err := s.GetExtendIfPresent(ctx, "counter", time.Minute)
+	if err != nil || !existed || value != "3" {
+		t.Fatalf("GetExtendIfPresent = %q, %v, %v; want \"3\", true", value, existed, err)
+	}
+
+	if ttl := s.client.PTTL(ctx.GetContext(), "counter").Val(); ttl <= time.Second || ttl > time.Minute {
+		t.Fatalf("ttl after extending = %v, want close to a minute", ttl)
+	}
+
+	value, existed, err = s.GetExtendIfPresent(ctx, "missing", time.Minute)
+	if err != nil || existed || value != "" {
+		t.Fatalf("GetExtendIfPresent on a missing key = %q, %v, %v; want \"\", false", value, existed, err)
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "missing").Val(); n != 0 {
+		t.Fatal("GetExtendIfPresent created the missing key")
+	}
+
+	for _, ttl := range []time.Duration{0, -time.Second, time.Microsecond} {
+		if _, _, err := s.GetExtendIfPresent(ctx, "counter", ttl); err == nil {
+			t.Fatalf("GetExtendIfPresent accepted ttl %v", ttl)
+		}
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "counter").Val(); n != 1 {
+		t.Fatal("an invalid ttl deleted the key")
+	}
+}
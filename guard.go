@@ -0,0 +1,143 @@
+package earedis
+
+import (
+	"encoding"
+	"fmt"
+	rdb "github.com/redis/go-redis/v9"
+	"strings"
+)
+
+// writeCommands — the commands rejected in ConnectConfig.ReadOnly mode. Lua scripts count as writes,
+// as every script this package runs modifies data.
+var writeCommands = newCommandSet([]string{
+	"SET", "SETNX", "SETEX", "PSETEX", "GETSET", "GETDEL", "GETEX", "MSET", "MSETNX", "APPEND", "SETRANGE",
+	"INCR", "INCRBY", "INCRBYFLOAT", "DECR", "DECRBY",
+	"DEL", "UNLINK", "EXPIRE", "PEXPIRE", "EXPIREAT", "PEXPIREAT", "PERSIST", "RENAME", "RENAMENX", "COPY", "MOVE",
+	"RESTORE", "FLUSHDB", "FLUSHALL",
+	"HSET", "HSETNX", "HMSET", "HDEL", "HINCRBY", "HINCRBYFLOAT", "HGETDEL",
+	"LPUSH", "RPUSH", "LPUSHX", "RPUSHX", "LPOP", "RPOP", "BLPOP", "BRPOP", "LMOVE", "BLMOVE", "RPOPLPUSH",
+	"LSET", "LREM", "LTRIM", "LINSERT",
+	"SADD", "SREM", "SPOP", "SMOVE", "SINTERSTORE", "SUNIONSTORE", "SDIFFSTORE",
+	"ZADD", "ZREM", "ZINCRBY", "ZPOPMIN", "ZPOPMAX", "ZREMRANGEBYSCORE", "ZREMRANGEBYRANK", "ZREMRANGEBYLEX",
+	"XADD", "XDEL", "XTRIM", "PFADD", "PFMERGE", "GEOADD", "SETBIT", "BITOP",
+	"EVAL", "EVALSHA", "FCALL",
+	"JSON.SET", "JSON.MSET", "JSON.MERGE", "JSON.DEL", "JSON.FORGET", "JSON.CLEAR", "JSON.TOGGLE",
+	"JSON.NUMINCRBY", "JSON.NUMMULTBY", "JSON.STRAPPEND", "JSON.ARRAPPEND", "JSON.ARRINSERT", "JSON.ARRPOP", "JSON.ARRTRIM",
+	"CONFIG SET", "SLOWLOG RESET",
+})
+
+// adminCommands — commands that change the server itself, refused unless ConnectConfig.AllowAdminCommands is set.
+var adminCommands = newCommandSet([]string{"CONFIG SET", "SLOWLOG RESET"})
+
+// subcommandParents — commands classified together with their first argument, e.g. "CONFIG SET".
+var subcommandParents = newCommandSet([]string{
+	"ACL", "CLIENT", "CLUSTER", "COMMAND", "CONFIG", "FUNCTION", "MEMORY", "MODULE", "OBJECT", "SCRIPT",
+	"SLOWLOG", "XGROUP", "XINFO",
+})
+
+// cmderCommand — returns the command name of cmd as checkCommand expects it, including the subcommand
+// for commands like CONFIG and SLOWLOG.
+func cmderCommand(cmd rdb.Cmder) string {
+	command := strings.ToUpper(cmd.Name())
+	if _, ok := subcommandParents[command]; !ok {
+		return command
+	}
+
+	args := cmd.Args()
+	if len(args) < 2 {
+		return command
+	}
+
+	return command + " " + strings.ToUpper(fmt.Sprint(args[1]))
+}
+
+// newCommandSet — builds an upper-cased lookup set of redis command names.
+func newCommandSet(commands []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(commands))
+	for _, command := range commands {
+		set[strings.ToUpper(strings.TrimSpace(command))] = struct{}{}
+	}
+
+	return set
+}
+
+// newSet — builds a lookup set of names.
+func newSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+
+	return set
+}
+
+// checkCommand — the guard every method passes before contacting redis.
+// Returns ErrCommandDisabled when the command, or for a subcommand like "CONFIG SET" its parent command,
+// is listed in ConnectConfig.DisallowedCommands or is an admin command without ConnectConfig.AllowAdminCommands,
+// and ErrReadOnlyMode for a write command when ConnectConfig.ReadOnly is set.
+func (s *Service) checkCommand(command string) error {
+	parent, _, _ := strings.Cut(command, " ")
+
+	_, disabled := s.disallowed[command]
+	if _, ok := s.disallowed[parent]; ok {
+		disabled = true
+	}
+
+	if disabled {
+		s.l.WarnT(s.traceName, "Rejected disallowed command", command)
+		return fmt.Errorf("%w: %s", ErrCommandDisabled, command)
+	}
+
+	if _, admin := adminCommands[command]; admin && !s.c.AllowAdminCommands {
+		s.l.WarnT(s.traceName, "Rejected admin command, AllowAdminCommands is not set", command)
+		return fmt.Errorf("%w: %s requires AllowAdminCommands", ErrCommandDisabled, command)
+	}
+
+	if _, write := writeCommands[command]; write && s.c.ReadOnly {
+		s.l.WarnT(s.traceName, "Rejected write command in read-only mode", command)
+		return fmt.Errorf("%w: %s", ErrReadOnlyMode, command)
+	}
+
+	return nil
+}
+
+// checkValueSize — rejects values larger than ConnectConfig.MaxValueSize with ErrValueTooLarge.
+// Only strings, byte slices and encoding.BinaryMarshaler values are measured; numbers and booleans are always small.
+func (s *Service) checkValueSize(key string, values ...interface{}) error {
+	if s.c.MaxValueSize <= 0 {
+		return nil
+	}
+
+	for _, value := range values {
+		size, err := valueSize(value)
+		if err != nil {
+			return err
+		}
+
+		if size > s.c.MaxValueSize {
+			s.l.ErrorT(s.traceName, "Rejected oversized value at key", key, size)
+			return fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrValueTooLarge, key, size, s.c.MaxValueSize)
+		}
+	}
+
+	return nil
+}
+
+// valueSize — returns the number of bytes a value occupies on the wire.
+func valueSize(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case string:
+		return len(v), nil
+	case []byte:
+		return len(v), nil
+	case encoding.BinaryMarshaler:
+		data, err := v.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+
+		return len(data), nil
+	default:
+		return 0, nil
+	}
+}
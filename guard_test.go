@@ -0,0 +1,129 @@
+package earedis
+
+import (
+	"context"
+	"errors"
+	rdb "github.com/redis/go-redis/v9"
+	"testing"
+)
+
+func TestDisallowedFlushDB(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.DisallowedCommands = []string{"flushdb"}
+	})
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "kept", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := s.FlushDB(ctx); !errors.Is(err, ErrCommandDisabled) {
+		t.Fatalf("FlushDB error = %v, want ErrCommandDisabled", err)
+	}
+
+	if got, err := s.Get(ctx, "kept"); err != nil || got != "value" {
+		t.Fatalf("Get = %q, %v; want \"value\"", got, err)
+	}
+}
+
+func TestCheckCommandParent(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{DisallowedCommands: []string{"CONFIG"}}, "test")
+
+	if err := s.checkCommand("CONFIG GET"); !errors.Is(err, ErrCommandDisabled) {
+		t.Fatalf("checkCommand(CONFIG GET) = %v, want ErrCommandDisabled", err)
+	}
+
+	if err := s.checkCommand("GET"); err != nil {
+		t.Fatalf("checkCommand(GET) = %v", err)
+	}
+}
+
+func TestMaxValueSize(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) { c.MaxValueSize = 8 })
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "small", "12345678", 0); err != nil {
+		t.Fatalf("Set under the limit: %v", err)
+	}
+
+	if err := s.Set(ctx, "large", "123456789", 0); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Set over the limit: %v, want ErrValueTooLarge", err)
+	}
+
+	if err := s.SAdd(ctx, "set", "ok", []byte("way too large")); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("SAdd over the limit: %v, want ErrValueTooLarge", err)
+	}
+
+	if n, err := s.client.Exists(ctx.GetContext(), "large", "set").Result(); err != nil || n != 0 {
+		t.Fatalf("rejected writes reached redis: %d keys, %v", n, err)
+	}
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.ReadOnly = true
+		// Admin commands must still be rejected as writes.
+		c.AllowAdminCommands = true
+	})
+	ctx := testContext(t)
+
+	if err := s.client.Set(ctx.GetContext(), "report", "value", 0).Err(); err != nil {
+		t.Fatalf("SET through the raw client: %v", err)
+	}
+
+	if got, err := s.Get(ctx, "report"); err != nil || got != "value" {
+		t.Fatalf("Get = %q, %v; want \"value\"", got, err)
+	}
+
+	if err := s.Set(ctx, "report", "changed", 0); !errors.Is(err, ErrReadOnlyMode) {
+		t.Fatalf("Set: %v, want ErrReadOnlyMode", err)
+	}
+
+	if _, err := s.Del(ctx, "report"); !errors.Is(err, ErrReadOnlyMode) {
+		t.Fatalf("Del: %v, want ErrReadOnlyMode", err)
+	}
+
+	if _, err := s.BlockingClient(); !errors.Is(err, ErrReadOnlyMode) {
+		t.Fatalf("BlockingClient: %v, want ErrReadOnlyMode", err)
+	}
+
+	pipe := s.NewResilientPipeline(0, 0)
+	get := pipe.Do(ctx, "get", "report")
+	if _, err := pipe.Exec(ctx); err != nil || get.Val() != "value" {
+		t.Fatalf("read pipeline = %v, %v; want \"value\"", get.Val(), err)
+	}
+
+	for _, args := range [][]interface{}{
+		{"set", "report", "changed"},
+		{"config", "set", "maxmemory-samples", "7"},
+		{"slowlog", "reset"},
+	} {
+		pipe.Do(ctx, args...)
+		if _, err := pipe.Exec(ctx); !errors.Is(err, ErrReadOnlyMode) {
+			t.Fatalf("pipeline %v: %v, want ErrReadOnlyMode", args, err)
+		}
+	}
+
+	if got, err := s.Get(ctx, "report"); err != nil || got != "value" {
+		t.Fatalf("Get after rejected writes = %q, %v; want \"value\"", got, err)
+	}
+}
+
+func TestCmderCommand(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		cmd  rdb.Cmder
+		want string
+	}{
+		{rdb.NewStatusCmd(ctx, "set", "k", "v"), "SET"},
+		{rdb.NewStatusCmd(ctx, "config", "set", "maxmemory", "1mb"), "CONFIG SET"},
+		{rdb.NewCmd(ctx, "SLOWLOG", "reset"), "SLOWLOG RESET"},
+		{rdb.NewIntCmd(ctx, "memory", "usage", "k"), "MEMORY USAGE"},
+		{rdb.NewCmd(ctx, "config"), "CONFIG"},
+	} {
+		if got := cmderCommand(tc.cmd); got != tc.want {
+			t.Errorf("cmderCommand(%v) = %q, want %q", tc.cmd.Args(), got, tc.want)
+		}
+	}
+}
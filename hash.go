@@ -0,0 +1,95 @@
+package earedis
+
+import (
+	"errors"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"strings"
+)
+
+// HGetDel — atomically returns the values of fields in the hash key and deletes them, e.g. to consume one-time tokens.
+// Values are returned in the order of fields; a missing field yields "". Uses HGETDEL on redis 7.4+ and a
+// MULTI/EXEC of HMGET and HDEL on older servers, or when a server of unknown version rejects HGETDEL.
+func (s *Service) HGetDel(ctx *eactx.Context, key string, fields ...string) (fieldValues []string, err error) {
+	defer s.reportReturned("HGetDel", key, &err)
+
+	if len(fields) == 0 {
+		return []string{}, nil
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	if s.requireVersion("HGETDEL", 7, 4) == nil {
+		values, err = s.hGetDel(ctx, key, fields)
+
+		// An unknown version was assumed new enough.
+		if err != nil && s.capabilities.Version == "" && strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+			s.l.DebugT(s.traceName, "HGETDEL is unknown to the server, falling back to HMGET and HDEL", key)
+			values, err = s.hMGetDel(ctx, key, fields)
+		}
+	} else {
+		values, err = s.hMGetDel(ctx, key, fields)
+	}
+
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get and delete hash fields", key, fields, err)
+		return nil, err
+	}
+
+	result := make([]string, len(values))
+	for i, value := range values {
+		if str, ok := value.(string); ok {
+			result[i] = str
+		}
+	}
+
+	return result, nil
+}
+
+// hGetDel — runs HGETDEL key FIELDS n field...
+func (s *Service) hGetDel(ctx *eactx.Context, key string, fields []string) ([]interface{}, error) {
+	if err := s.checkCommand("HGETDEL"); err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, 0, len(fields)+4)
+	args = append(args, "hgetdel", key, "fields", len(fields))
+	for _, field := range fields {
+		args = append(args, field)
+	}
+
+	values, err := s.client.Do(ctx.GetContext(), args...).Slice()
+	if err != nil && !errors.Is(err, rdb.Nil) {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// hMGetDel — the HGETDEL fallback for servers before 7.4: HMGET and HDEL in one transaction.
+func (s *Service) hMGetDel(ctx *eactx.Context, key string, fields []string) ([]interface{}, error) {
+	if err := s.checkCommand("HMGET"); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCommand("HDEL"); err != nil {
+		return nil, err
+	}
+
+	var get *rdb.SliceCmd
+	_, err := s.client.TxPipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		get = pipe.HMGet(ctx.GetContext(), key, fields...)
+		pipe.HDel(ctx.GetContext(), key, fields...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return get.Val(), nil
+}
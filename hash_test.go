@@ -0,0 +1,64 @@
+package earedis
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testHGetDel — consumes tokens from a hash, including fields that do not exist.
+func testHGetDel(t *testing.T, s *Service) {
+	ctx := testContext(t)
+
+	if err := s.client.HSet(ctx.GetContext(), "tokens", "step1", "t1", "step2", "t2", "step3", "t3").Err(); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+
+	values, err := s.HGetDel(ctx, "tokens", "step1", "missing", "step3")
+	if err != nil {
+		t.Fatalf("HGetDel: %v", err)
+	}
+
+	if want := []string{"t1", "", "t3"}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("HGetDel = %q, want %q", values, want)
+	}
+
+	remaining := s.client.HGetAll(ctx.GetContext(), "tokens").Val()
+	if want := map[string]string{"step2": "t2"}; !reflect.DeepEqual(remaining, want) {
+		t.Fatalf("remaining fields %v, want %v", remaining, want)
+	}
+
+	// A consumed token is gone.
+	values, err = s.HGetDel(ctx, "tokens", "step1", "step2")
+	if err != nil || !reflect.DeepEqual(values, []string{"", "t2"}) {
+		t.Fatalf("second HGetDel = %q, %v; want [\"\" \"t2\"]", values, err)
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "tokens").Val(); n != 0 {
+		t.Fatal("the emptied hash still exists")
+	}
+
+	values, err = s.HGetDel(ctx, "absent", "step1")
+	if err != nil || !reflect.DeepEqual(values, []string{""}) {
+		t.Fatalf("HGetDel on a missing key = %q, %v; want [\"\"]", values, err)
+	}
+}
+
+func TestHGetDel(t *testing.T) {
+	testHGetDel(t, newTestService(t))
+}
+
+func TestHGetDelBeforeRedis74(t *testing.T) {
+	s := newTestService(t)
+	s.capabilities = ServerCapabilities{Version: "7.2.4", Protocol: 3, Mode: "standalone"}
+
+	testHGetDel(t, s)
+}
+
+func TestHGetDelUnknownVersion(t *testing.T) {
+	s := newTestService(t)
+
+	// Whether or not the server knows HGETDEL, it falls back when needed.
+	s.capabilities = ServerCapabilities{}
+
+	testHGetDel(t, s)
+}
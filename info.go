@@ -0,0 +1,94 @@
+package earedis
+
+import (
+	"context"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// parseInfo — parses "name:value" lines as returned by INFO and CLUSTER INFO, skipping comments and blank lines.
+func parseInfo(info string) map[string]string {
+	result := make(map[string]string)
+
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		result[name] = value
+	}
+
+	return result
+}
+
+// parseKeyspaceStats — extracts keyspace_hits and keyspace_misses from an INFO stats reply.
+func parseKeyspaceStats(info string) (hits, misses int64, err error) {
+	stats := parseInfo(info)
+
+	hits, err = strconv.ParseInt(stats["keyspace_hits"], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("earedis: parse keyspace_hits: %w", err)
+	}
+
+	misses, err = strconv.ParseInt(stats["keyspace_misses"], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("earedis: parse keyspace_misses: %w", err)
+	}
+
+	return hits, misses, nil
+}
+
+// KeyspaceStats — returns the server-wide keyspace hits and misses from INFO stats, summed over all masters
+// in cluster mode, and the hit ratio hits/(hits+misses), which is 0 on a server that served no lookups yet.
+func (s *Service) KeyspaceStats(ctx *eactx.Context) (hits, misses int64, ratio float64, err error) {
+	defer s.reportReturned("KeyspaceStats", "", &err)
+
+	if err := s.checkCommand("INFO"); err != nil {
+		return 0, 0, 0, err
+	}
+
+	var mu sync.Mutex
+	err = s.forEachNode(ctx.GetContext(), func(ctx context.Context, client rdb.Cmdable) error {
+		info, err := client.Info(ctx, "stats").Result()
+		if err != nil {
+			return err
+		}
+
+		nodeHits, nodeMisses, err := parseKeyspaceStats(info)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		hits += nodeHits
+		misses += nodeMisses
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get keyspace stats", err)
+		return 0, 0, 0, err
+	}
+
+	return hits, misses, hitRatio(hits, misses), nil
+}
+
+// hitRatio — returns hits/(hits+misses), or 0 when there were no lookups.
+func hitRatio(hits, misses int64) float64 {
+	total := hits + misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(total)
+}
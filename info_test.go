@@ -0,0 +1,50 @@
+package earedis
+
+import (
+	"strings"
+	"testing"
+)
+
+// capturedInfoStats — an INFO stats reply captured from redis 7.2, trimmed.
+const capturedInfoStats = "# Stats\r\n" +
+	"total_connections_received:12\r\n" +
+	"total_commands_processed:4821\r\n" +
+	"instantaneous_ops_per_sec:3\r\n" +
+	"expired_keys:17\r\n" +
+	"evicted_keys:0\r\n" +
+	"keyspace_hits:3000\r\n" +
+	"keyspace_misses:1000\r\n" +
+	"pubsub_channels:0\r\n" +
+	"latest_fork_usec:0\r\n"
+
+// capturedFreshInfoStats — the same reply from a server that served no lookups yet.
+const capturedFreshInfoStats = "# Stats\r\n" +
+	"total_connections_received:1\r\n" +
+	"total_commands_processed:1\r\n" +
+	"keyspace_hits:0\r\n" +
+	"keyspace_misses:0\r\n"
+
+func TestParseKeyspaceStats(t *testing.T) {
+	hits, misses, err := parseKeyspaceStats(capturedInfoStats)
+	if err != nil || hits != 3000 || misses != 1000 {
+		t.Fatalf("parseKeyspaceStats = %d, %d, %v; want 3000, 1000", hits, misses, err)
+	}
+
+	if ratio := hitRatio(hits, misses); ratio != 0.75 {
+		t.Fatalf("hitRatio = %v, want 0.75", ratio)
+	}
+
+	hits, misses, err = parseKeyspaceStats(capturedFreshInfoStats)
+	if err != nil || hits != 0 || misses != 0 {
+		t.Fatalf("fresh parseKeyspaceStats = %d, %d, %v; want 0, 0", hits, misses, err)
+	}
+
+	if ratio := hitRatio(hits, misses); ratio != 0 {
+		t.Fatalf("fresh hitRatio = %v, want 0", ratio)
+	}
+
+	_, _, err = parseKeyspaceStats("# Stats\r\nkeyspace_hits:1\r\n")
+	if err == nil || !strings.Contains(err.Error(), "keyspace_misses") {
+		t.Fatalf("parseKeyspaceStats without misses: %v, want a keyspace_misses error", err)
+	}
+}
@@ -0,0 +1,134 @@
+package earedis
+
+import (
+	"errors"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"time"
+)
+
+// initLockTTL — how long an InitOnce winner may run init before other instances may take over.
+const initLockTTL = 30 * time.Second
+
+// initUnlockScript — deletes the lock KEYS[1] only while it still holds the token ARGV[1].
+var initUnlockScript = rdb.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+
+return 0
+`)
+
+// InitOnce — returns the value of key, initializing it exactly once across instances: the instance that wins
+// the "<key>:init" lock runs init and stores its result with ttl (0 keeps it forever), while the others wait
+// for the stored value. created reports whether this call ran init. When init fails nothing is stored,
+// the lock is released and the error is returned, so another instance may retry. A winner that does not
+// finish within 30 seconds loses the lock to the next waiter.
+func (s *Service) InitOnce(ctx *eactx.Context, key string, init func() (string, error), ttl time.Duration) (value string, created bool, err error) {
+	defer s.reportReturned("InitOnce", key, &err)
+
+	if err := s.checkCommand("GET"); err != nil {
+		return "", false, err
+	}
+
+	// SetNX sends SETNX, or SET ... NX with a ttl.
+	if err := s.checkCommand("SET"); err != nil {
+		return "", false, err
+	}
+
+	if err := s.checkCommand("SETNX"); err != nil {
+		return "", false, err
+	}
+
+	if err := s.checkCommand("EVAL"); err != nil {
+		return "", false, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+
+	lockKey := key + ":init"
+	token, err := newToken()
+	if err != nil {
+		return "", false, err
+	}
+
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+
+	for {
+		value, err := s.client.Get(ctx.GetContext(), key).Result()
+		if err == nil {
+			return value, false, nil
+		}
+
+		if !errors.Is(err, rdb.Nil) {
+			s.l.ErrorT(s.traceName, "Failed to get key", key, err)
+			return "", false, err
+		}
+
+		locked, err := s.client.SetNX(ctx.GetContext(), lockKey, token, initLockTTL).Result()
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to lock key for init", key, err)
+			return "", false, err
+		}
+
+		if locked {
+			return s.runInit(ctx, key, lockKey, token, init, ttl)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// runInit — runs init under the held lock and stores its result, releasing the lock either way.
+func (s *Service) runInit(ctx *eactx.Context, key, lockKey, token string, init func() (string, error), ttl time.Duration) (string, bool, error) {
+	defer func() {
+		if err := initUnlockScript.Run(ctx.GetContext(), s.client, []string{lockKey}, token).Err(); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to unlock key after init", key, err)
+		}
+	}()
+
+	// The previous winner may have stored the value and released the lock since the last read.
+	value, err := s.client.Get(ctx.GetContext(), key).Result()
+	if err == nil {
+		return value, false, nil
+	}
+
+	if !errors.Is(err, rdb.Nil) {
+		s.l.ErrorT(s.traceName, "Failed to get key", key, err)
+		return "", false, err
+	}
+
+	value, err = init()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to init key", key, err)
+		return "", false, err
+	}
+
+	// NX, in case the lock expired and a slower winner's value is already stored.
+	stored, err := s.client.SetNX(ctx.GetContext(), key, value, ttl).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to store initialized key", key, err)
+		return "", false, err
+	}
+
+	if !stored {
+		value, err = s.client.Get(ctx.GetContext(), key).Result()
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to get key", key, err)
+			return "", false, err
+		}
+
+		return value, false, nil
+	}
+
+	s.publishInvalidation(ctx, key)
+	return value, true, nil
+}
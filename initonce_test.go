@@ -0,0 +1,91 @@
+package earedis
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInitOnceRunsInitOnce(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	var runs atomic.Int64
+	init := func() (string, error) {
+		runs.Add(1)
+
+		// Long enough for every other goroutine to find the lock taken.
+		time.Sleep(100 * time.Millisecond)
+		return "default", nil
+	}
+
+	const instances = 8
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int64
+		errs    = make(chan error, instances)
+	)
+
+	for i := 0; i < instances; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			value, won, err := s.InitOnce(ctx, "config", init, 0)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if value != "default" {
+				errs <- errors.New("got value " + value)
+				return
+			}
+
+			if won {
+				created.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("InitOnce: %v", err)
+	}
+
+	if runs.Load() != 1 || created.Load() != 1 {
+		t.Fatalf("init ran %d times and %d callers created the key, want 1 and 1", runs.Load(), created.Load())
+	}
+}
+
+func TestInitOnceFailedInitLeavesNothing(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	failure := errors.New("source unavailable")
+	if _, _, err := s.InitOnce(ctx, "config", func() (string, error) { return "", failure }, 0); !errors.Is(err, failure) {
+		t.Fatalf("InitOnce with a failing init: %v, want the init error", err)
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "config", "config:init").Val(); n != 0 {
+		t.Fatalf("a failed init left %d keys behind", n)
+	}
+
+	value, created, err := s.InitOnce(ctx, "config", func() (string, error) { return "retried", nil }, time.Minute)
+	if err != nil || !created || value != "retried" {
+		t.Fatalf("InitOnce after a failure = %q, %v, %v; want \"retried\", true", value, created, err)
+	}
+}
+
+func TestInitOnceRespectsDisallowedSetNX(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{DisallowedCommands: []string{"SETNX"}}, "test")
+
+	_, _, err := s.InitOnce(testContext(t), "config", func() (string, error) { return "default", nil }, 0)
+	if !errors.Is(err, ErrCommandDisabled) {
+		t.Fatalf("InitOnce with SETNX disallowed: %v, want ErrCommandDisabled", err)
+	}
+}
@@ -0,0 +1,90 @@
+package earedis
+
+import (
+	"encoding/json"
+	"github.com/eris-apple/eactx"
+)
+
+// invalidation — the message broadcast on ConnectConfig.InvalidationChannel after a write.
+type invalidation struct {
+	Instance string   `json:"instance"`
+	Keys     []string `json:"keys"`
+}
+
+// publishInvalidation — tells the other instances that keys changed. The write itself already succeeded,
+// so a refused or failed broadcast is only logged.
+func (s *Service) publishInvalidation(ctx *eactx.Context, keys ...string) {
+	if s.c.InvalidationChannel == "" || len(keys) == 0 {
+		return
+	}
+
+	if err := s.checkCommand("PUBLISH"); err != nil {
+		return
+	}
+
+	message, err := json.Marshal(invalidation{Instance: s.instanceID, Keys: keys})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to encode invalidation", keys, err)
+		return
+	}
+
+	if err := s.client.Publish(ctx.GetContext(), s.channel("", s.c.InvalidationChannel), message).Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to publish invalidation", keys, err)
+	}
+}
+
+// StartInvalidationListener — subscribes to ConnectConfig.InvalidationChannel and calls evict for every key
+// written by Set or deleted by Del/Unlink on another instance, e.g. to drop it from a local cache layer.
+// Keys are the full redis keys, tenant namespace included. Messages of this instance are ignored.
+// The listener runs in the background until ctx is done.
+func (s *Service) StartInvalidationListener(ctx *eactx.Context, evict func(key string)) (err error) {
+	defer s.reportReturned("StartInvalidationListener", "", &err)
+
+	if s.c.InvalidationChannel == "" {
+		return nil
+	}
+
+	if err := s.checkCommand("SUBSCRIBE"); err != nil {
+		return err
+	}
+
+	pubsub := s.client.Subscribe(ctx.GetContext(), s.channel("", s.c.InvalidationChannel))
+	if _, err := pubsub.Receive(ctx.GetContext()); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to subscribe to invalidations", s.c.InvalidationChannel, err)
+		_ = pubsub.Close()
+		return err
+	}
+
+	go func() {
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var inv invalidation
+				if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+					s.l.ErrorT(s.traceName, "Failed to decode invalidation", err)
+					continue
+				}
+
+				if inv.Instance == s.instanceID {
+					continue
+				}
+
+				for _, key := range inv.Keys {
+					evict(key)
+				}
+			}
+		}
+	}()
+
+	s.l.InfoT(s.traceName, "Listening for invalidations", s.c.InvalidationChannel)
+	return nil
+}
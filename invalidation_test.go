@@ -0,0 +1,122 @@
+package earedis
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// localCache — a process-local cache layer kept coherent by an invalidation listener.
+type localCache struct {
+	mu      sync.Mutex
+	values  map[string]string
+	evicted chan string
+}
+
+func newLocalCache() *localCache {
+	return &localCache{values: make(map[string]string), evicted: make(chan string, 16)}
+}
+
+func (c *localCache) put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+}
+
+func (c *localCache) has(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.values[key]
+	return ok
+}
+
+func (c *localCache) evict(key string) {
+	c.mu.Lock()
+	delete(c.values, key)
+	c.mu.Unlock()
+
+	c.evicted <- key
+}
+
+// waitEvicted — waits for the cache to evict key.
+func (c *localCache) waitEvicted(t *testing.T, key string) {
+	t.Helper()
+
+	select {
+	case got := <-c.evicted:
+		if got != key {
+			t.Fatalf("evicted %s, want %s", got, key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s was not evicted", key)
+	}
+}
+
+func TestInvalidationAcrossInstances(t *testing.T) {
+	configure := func(c *ConnectConfig) {
+		c.InvalidationChannel = "invalidations"
+	}
+	a, b := newTestService(t, configure), newTestService(t, configure)
+	ctx := testContext(t)
+
+	cacheA, cacheB := newLocalCache(), newLocalCache()
+	if err := a.StartInvalidationListener(ctx, cacheA.evict); err != nil {
+		t.Fatalf("StartInvalidationListener a: %v", err)
+	}
+
+	if err := b.StartInvalidationListener(ctx, cacheB.evict); err != nil {
+		t.Fatalf("StartInvalidationListener b: %v", err)
+	}
+
+	cacheA.put("user:1", "old")
+	cacheB.put("user:1", "old")
+
+	// A write on a evicts the copy of b, while a ignores its own message.
+	if err := a.Set(ctx, "user:1", "new", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	cacheA.put("user:1", "new")
+
+	cacheB.waitEvicted(t, "user:1")
+	if cacheB.has("user:1") {
+		t.Fatal("b still caches user:1")
+	}
+
+	select {
+	case key := <-cacheA.evicted:
+		t.Fatalf("a evicted %s on its own write", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// A delete on b evicts the copy of a.
+	if _, err := b.Del(ctx, "user:1"); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	cacheA.waitEvicted(t, "user:1")
+	if cacheA.has("user:1") {
+		t.Fatal("a still caches user:1")
+	}
+
+	select {
+	case key := <-cacheB.evicted:
+		t.Fatalf("b evicted %s on its own delete", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestInvalidationHonorsDisallowedCommands(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{
+		InvalidationChannel: "invalidations",
+		DisallowedCommands:  []string{"PUBLISH", "SUBSCRIBE"},
+	}, "test")
+	ctx := testContext(t)
+
+	// Without a client, anything but the refusal would panic.
+	s.publishInvalidation(ctx, "key")
+
+	if err := s.StartInvalidationListener(ctx, func(string) {}); !errors.Is(err, ErrCommandDisabled) {
+		t.Fatalf("StartInvalidationListener: %v, want ErrCommandDisabled", err)
+	}
+}
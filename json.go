@@ -0,0 +1,270 @@
+package earedis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONGetBatch — fetches every key of targets with a single MGET and decodes each value into its
+// destination pointer. Missing keys leave their destination untouched; decode failures are collected
+// and returned together (errors.Join) after all other keys have been decoded.
+// In cluster mode all keys must hash to the same slot.
+func (s *Service) JSONGetBatch(ctx *eactx.Context, targets map[string]interface{}) (err error) {
+	defer s.reportReturned("JSONGetBatch", "", &err)
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	for key, target := range targets {
+		if err := checkTarget(target); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if err := s.checkCommand("MGET"); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(targets))
+	for key := range targets {
+		keys = append(keys, key)
+	}
+
+	tenantKeys, err := s.tenantKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	values, err := s.client.MGet(ctx.GetContext(), tenantKeys...).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get keys", keys, err)
+		return err
+	}
+
+	var errs []error
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(str), targets[keys[i]]); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to decode key", keys[i], err)
+			errs = append(errs, fmt.Errorf("earedis: decode %s: %w", keys[i], err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// maxOptimisticRetries — attempts of a WATCH-based read-modify-write before giving up with ErrConflict.
+const maxOptimisticRetries = 16
+
+// JSONUpdate — performs an optimistic read-modify-write of the JSON document at key: the document is
+// decoded into out, mutate edits out, and the result is written back (keeping the TTL) only if the key
+// was not changed in the meantime. On a conflict out is reset and the whole cycle, mutate included,
+// is retried; after maxOptimisticRetries conflicts ErrConflict is returned. A missing key returns ErrNotFound,
+// an error from mutate aborts the update and is returned as is.
+func (s *Service) JSONUpdate(ctx *eactx.Context, key string, out interface{}, mutate func() error) (err error) {
+	defer s.reportReturned("JSONUpdate", key, &err)
+
+	if err := checkTarget(out); err != nil {
+		return err
+	}
+
+	if err := s.checkCommand("GET"); err != nil {
+		return err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkCommand("SET"); err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < maxOptimisticRetries; attempt++ {
+		err := s.client.Watch(ctx.GetContext(), func(tx *rdb.Tx) error {
+			data, err := tx.Get(ctx.GetContext(), key).Bytes()
+			if errors.Is(err, rdb.Nil) {
+				return fmt.Errorf("%w: %s", ErrNotFound, key)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			target := reflect.ValueOf(out).Elem()
+			target.Set(reflect.Zero(target.Type()))
+
+			if err := json.Unmarshal(data, out); err != nil {
+				return err
+			}
+
+			if err := mutate(); err != nil {
+				return err
+			}
+
+			encoded, err := json.Marshal(out)
+			if err != nil {
+				return err
+			}
+
+			if err := s.checkValueSize(key, encoded); err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+				pipe.SetArgs(ctx.GetContext(), key, encoded, rdb.SetArgs{KeepTTL: true})
+				return nil
+			})
+			return err
+		}, key)
+
+		if errors.Is(err, rdb.TxFailedErr) {
+			s.l.DebugT(s.traceName, "Retrying conflicting update of key", key, attempt+1)
+			continue
+		}
+
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to update key", key, err)
+			return err
+		}
+
+		return nil
+	}
+
+	s.l.ErrorT(s.traceName, "Gave up updating contended key", key)
+	return fmt.Errorf("%w: %s", ErrConflict, key)
+}
+
+// JSONSetIfField — replaces the JSON document at key with v (expiring after ttl, 0 keeps it forever) only if
+// the field addressed by the JSON Pointer jsonPointerField (RFC 6901, e.g. "/stock/count") currently equals
+// expected. String fields are compared as is, other values by their JSON encoding (e.g. "5", "true").
+// The check and the write form a WATCH transaction, retried on concurrent changes up to maxOptimisticRetries
+// times. Returns whether the document was replaced; a missing key or field never matches.
+func (s *Service) JSONSetIfField(ctx *eactx.Context, key, jsonPointerField, expected string, v interface{}, ttl time.Duration) (set bool, err error) {
+	defer s.reportReturned("JSONSetIfField", key, &err)
+
+	if err := s.checkCommand("GET"); err != nil {
+		return false, err
+	}
+
+	if err := s.checkCommand("SET"); err != nil {
+		return false, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.checkValueSize(key, encoded); err != nil {
+		return false, err
+	}
+
+	for attempt := 0; attempt < maxOptimisticRetries; attempt++ {
+		applied := false
+		err := s.client.Watch(ctx.GetContext(), func(tx *rdb.Tx) error {
+			data, err := tx.Get(ctx.GetContext(), key).Bytes()
+			if errors.Is(err, rdb.Nil) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			var doc interface{}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				return err
+			}
+
+			field, ok := jsonPointer(doc, jsonPointerField)
+			if !ok || !fieldEquals(field, expected) {
+				return nil
+			}
+
+			_, err = tx.TxPipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+				pipe.Set(ctx.GetContext(), key, encoded, ttl)
+				return nil
+			})
+			applied = err == nil
+			return err
+		}, key)
+
+		if errors.Is(err, rdb.TxFailedErr) {
+			s.l.DebugT(s.traceName, "Retrying conflicting conditional set of key", key, attempt+1)
+			continue
+		}
+
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to conditionally set key", key, err)
+			return false, err
+		}
+
+		return applied, nil
+	}
+
+	s.l.ErrorT(s.traceName, "Gave up conditionally setting contended key", key)
+	return false, fmt.Errorf("%w: %s", ErrConflict, key)
+}
+
+// jsonPointer — resolves an RFC 6901 JSON Pointer against a decoded JSON document.
+func jsonPointer(doc interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			doc = value
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			doc = node[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return doc, true
+}
+
+// fieldEquals — compares a decoded JSON value with its expected textual form.
+func fieldEquals(field interface{}, expected string) bool {
+	if str, ok := field.(string); ok {
+		return str == expected
+	}
+
+	encoded, err := json.Marshal(field)
+	return err == nil && string(encoded) == expected
+}
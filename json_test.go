@@ -0,0 +1,213 @@
+package earedis
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestJSONGetBatchStructAndSlice(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "user", `{"name":"ann","age":31}`, 0); err != nil {
+		t.Fatalf("Set user: %v", err)
+	}
+
+	if err := s.Set(ctx, "tags", `["a","b","c"]`, 0); err != nil {
+		t.Fatalf("Set tags: %v", err)
+	}
+
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	var u user
+	var tags []string
+	missing := "untouched"
+	err := s.JSONGetBatch(ctx, map[string]interface{}{"user": &u, "tags": &tags, "missing": &missing})
+	if err != nil {
+		t.Fatalf("JSONGetBatch: %v", err)
+	}
+
+	if u != (user{Name: "ann", Age: 31}) {
+		t.Fatalf("user = %+v", u)
+	}
+
+	if !reflect.DeepEqual(tags, []string{"a", "b", "c"}) {
+		t.Fatalf("tags = %v", tags)
+	}
+
+	if missing != "untouched" {
+		t.Fatalf("missing key changed its target to %q", missing)
+	}
+}
+
+func TestJSONUpdateRetriesOnConflict(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	type counter struct {
+		N int `json:"n"`
+	}
+
+	if err := s.Set(ctx, "counter", `{"n":0}`, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	started, proceed := make(chan struct{}), make(chan struct{})
+	attempts := 0
+	done := make(chan error, 1)
+
+	go func() {
+		var c counter
+		done <- s.JSONUpdate(ctx, "counter", &c, func() error {
+			attempts++
+			if attempts == 1 {
+				close(started)
+				<-proceed
+			}
+
+			c.N++
+			return nil
+		})
+	}()
+
+	// The second writer commits while the first one is between its read and its write.
+	<-started
+	var c counter
+	if err := s.JSONUpdate(ctx, "counter", &c, func() error { c.N++; return nil }); err != nil {
+		t.Fatalf("JSONUpdate of the second writer: %v", err)
+	}
+	close(proceed)
+
+	if err := <-done; err != nil {
+		t.Fatalf("JSONUpdate of the first writer: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("first writer ran mutate %d times, want 2", attempts)
+	}
+
+	if err := s.JSONGet(ctx, "counter", &c); err != nil || c.N != 2 {
+		t.Fatalf("counter = %+v, %v; want n 2", c, err)
+	}
+}
+
+func TestJSONSetIfFieldContention(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	type item struct {
+		Stock struct {
+			Count int `json:"count"`
+		} `json:"stock"`
+		Writer string `json:"writer"`
+	}
+
+	for round := 0; round < 20; round++ {
+		if err := s.Set(ctx, "item", `{"stock":{"count":5}}`, 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		var (
+			wg      sync.WaitGroup
+			start   = make(chan struct{})
+			applied = make([]bool, 2)
+			errs    = make([]error, 2)
+		)
+
+		for i := range applied {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				var next item
+				next.Stock.Count = 4
+				next.Writer = fmt.Sprint("writer-", i)
+
+				<-start
+				applied[i], errs[i] = s.JSONSetIfField(ctx, "item", "/stock/count", "5", next, 0)
+			}(i)
+		}
+
+		close(start)
+		wg.Wait()
+
+		if errs[0] != nil || errs[1] != nil {
+			t.Fatalf("round %d: JSONSetIfField errors %v", round, errs)
+		}
+
+		if applied[0] == applied[1] {
+			t.Fatalf("round %d: applied %v, want exactly one writer", round, applied)
+		}
+
+		winner := 0
+		if applied[1] {
+			winner = 1
+		}
+
+		var got item
+		if err := s.JSONGet(ctx, "item", &got); err != nil || got.Writer != fmt.Sprint("writer-", winner) {
+			t.Fatalf("round %d: document %+v, %v; want the write of writer-%d", round, got, err, winner)
+		}
+	}
+
+	applied, err := s.JSONSetIfField(ctx, "item", "/stock/count", "5", item{}, 0)
+	if err != nil || applied {
+		t.Fatalf("JSONSetIfField with a stale expectation = %v, %v; want false", applied, err)
+	}
+
+	applied, err = s.JSONSetIfField(ctx, "missing", "/stock/count", "5", item{}, 0)
+	if err != nil || applied {
+		t.Fatalf("JSONSetIfField on a missing key = %v, %v; want false", applied, err)
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	// The example document of RFC 6901, section 5.
+	var doc interface{} = map[string]interface{}{
+		"foo":  []interface{}{"bar", "baz"},
+		"":     0.0,
+		"a/b":  1.0,
+		"m~n":  8.0,
+		"k\"l": 6.0,
+		"nested": map[string]interface{}{
+			"ok": true,
+		},
+	}
+
+	tests := []struct {
+		pointer string
+		want    interface{}
+		ok      bool
+	}{
+		{"", doc, true},
+		{"/foo/0", "bar", true},
+		{"/foo/1", "baz", true},
+		{"/", 0.0, true},
+		{"/a~1b", 1.0, true},
+		{"/m~0n", 8.0, true},
+		{"/k\"l", 6.0, true},
+		{"/nested/ok", true, true},
+		{"/foo/2", nil, false},
+		{"/foo/-1", nil, false},
+		{"/foo/x", nil, false},
+		{"/missing", nil, false},
+		{"/nested/ok/deeper", nil, false},
+		{"foo", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := jsonPointer(doc, tt.pointer)
+		if ok != tt.ok || ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jsonPointer(%q) = %v, %v; want %v, %v", tt.pointer, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if !fieldEquals(5.0, "5") || !fieldEquals("5", "5") || fieldEquals(5.0, `"5"`) || !fieldEquals(true, "true") {
+		t.Error("fieldEquals compares values by their JSON encoding, strings as is")
+	}
+}
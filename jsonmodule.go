@@ -0,0 +1,169 @@
+package earedis
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	"strconv"
+	"strings"
+)
+
+// redisJSONModule — the name RedisJSON registers itself under.
+const redisJSONModule = "ReJSON"
+
+// moduleError — turns the "unknown command" reply of a server lacking the module into ErrModuleNotLoaded.
+func moduleError(module string, err error) error {
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+		return fmt.Errorf("%w: %s: %s", ErrModuleNotLoaded, module, err)
+	}
+
+	return err
+}
+
+// JSONModuleNumIncrBy — increments the number at path of the RedisJSON document at key by delta
+// and returns the new value. For a JSONPath ("$...") matching several numbers the first one is returned.
+func (s *Service) JSONModuleNumIncrBy(ctx *eactx.Context, key, path string, delta float64) (value float64, err error) {
+	defer s.reportReturned("JSONModuleNumIncrBy", key, &err)
+
+	if err := s.checkCommand("JSON.NUMINCRBY"); err != nil {
+		return 0, err
+	}
+
+	if err := s.requireModule(redisJSONModule); err != nil {
+		return 0, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := s.client.JSONNumIncrBy(ctx.GetContext(), key, path, delta).Result()
+	if err != nil {
+		err = moduleError(redisJSONModule, err)
+		s.l.ErrorT(s.traceName, "Failed to increment JSON number at key", key, path, err)
+		return 0, err
+	}
+
+	// JSONPath replies with an array of values (null for non-numbers), legacy paths with the bare value.
+	if strings.HasPrefix(result, "[") {
+		var values []*float64
+		if err := json.Unmarshal([]byte(result), &values); err != nil {
+			return 0, err
+		}
+
+		if len(values) == 0 || values[0] == nil {
+			return 0, fmt.Errorf("%w: no number at %s of %s", ErrNotFound, path, key)
+		}
+
+		return *values[0], nil
+	}
+
+	return strconv.ParseFloat(result, 64)
+}
+
+// JSONModuleArrAppend — appends values, encoded as JSON, to the array at path of the RedisJSON document at key.
+// Returns the new length of every array matched by path.
+func (s *Service) JSONModuleArrAppend(ctx *eactx.Context, key, path string, values ...interface{}) (lengths []int64, err error) {
+	defer s.reportReturned("JSONModuleArrAppend", key, &err)
+
+	if err := s.checkCommand("JSON.ARRAPPEND"); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireModule(redisJSONModule); err != nil {
+		return nil, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]interface{}, len(values))
+	for i, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded[i] = data
+	}
+
+	if err := s.checkValueSize(key, encoded...); err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.JSONArrAppend(ctx.GetContext(), key, path, encoded...).Result()
+	if err != nil {
+		err = moduleError(redisJSONModule, err)
+		s.l.ErrorT(s.traceName, "Failed to append to JSON array at key", key, path, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// JSONModuleStrAppend — appends value to the string at path of the RedisJSON document at key.
+// Returns the new length of every string matched by path, nil for matches that are not strings.
+func (s *Service) JSONModuleStrAppend(ctx *eactx.Context, key, path, value string) (lengths []*int64, err error) {
+	defer s.reportReturned("JSONModuleStrAppend", key, &err)
+
+	if err := s.checkCommand("JSON.STRAPPEND"); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireModule(redisJSONModule); err != nil {
+		return nil, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkValueSize(key, value); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.JSONStrAppend(ctx.GetContext(), key, path, string(encoded)).Result()
+	if err != nil {
+		err = moduleError(redisJSONModule, err)
+		s.l.ErrorT(s.traceName, "Failed to append to JSON string at key", key, path, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// JSONModuleDel — deletes the values at path of the RedisJSON document at key and returns how many were deleted.
+func (s *Service) JSONModuleDel(ctx *eactx.Context, key, path string) (deleted int64, err error) {
+	defer s.reportReturned("JSONModuleDel", key, &err)
+
+	if err := s.checkCommand("JSON.DEL"); err != nil {
+		return 0, err
+	}
+
+	if err := s.requireModule(redisJSONModule); err != nil {
+		return 0, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := s.client.JSONDel(ctx.GetContext(), key, path).Result()
+	if err != nil {
+		err = moduleError(redisJSONModule, err)
+		s.l.ErrorT(s.traceName, "Failed to delete JSON path at key", key, path, err)
+		return 0, err
+	}
+
+	return result, nil
+}
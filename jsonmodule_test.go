@@ -0,0 +1,68 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONModuleNumIncrByAndArrAppend(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if err := s.client.JSONSet(ctx.GetContext(), "doc", "$", `{"count":1,"tags":["a","b"]}`).Err(); err != nil {
+		skipUnsupported(t, moduleError(redisJSONModule, err))
+		t.Fatalf("JSON.SET: %v", err)
+	}
+
+	count, err := s.JSONModuleNumIncrBy(ctx, "doc", "$.count", 2.5)
+	skipUnsupported(t, err)
+	if err != nil || count != 3.5 {
+		t.Fatalf("JSONModuleNumIncrBy($.count) = %v, %v; want 3.5", count, err)
+	}
+
+	if count, err = s.JSONModuleNumIncrBy(ctx, "doc", ".count", -1); err != nil || count != 2.5 {
+		t.Fatalf("JSONModuleNumIncrBy(.count) = %v, %v; want 2.5", count, err)
+	}
+
+	lengths, err := s.JSONModuleArrAppend(ctx, "doc", "$.tags", "c")
+	if err != nil || len(lengths) != 1 || lengths[0] != 3 {
+		t.Fatalf("JSONModuleArrAppend = %v, %v; want [3]", lengths, err)
+	}
+
+	got, err := s.client.JSONGet(ctx.GetContext(), "doc", "$").Result()
+	if err != nil {
+		t.Fatalf("JSON.GET: %v", err)
+	}
+
+	if want := `[{"count":2.5,"tags":["a","b","c"]}]`; got != want {
+		t.Fatalf("document = %s, want %s", got, want)
+	}
+}
+
+func TestJSONModuleNotLoaded(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+	s.capabilities = ServerCapabilities{Version: "7.2.4", Protocol: 3, Mode: "standalone"}
+	ctx := testContext(t)
+
+	if _, err := s.JSONModuleNumIncrBy(ctx, "doc", "$.count", 1); !errors.Is(err, ErrModuleNotLoaded) {
+		t.Fatalf("JSONModuleNumIncrBy: %v, want ErrModuleNotLoaded", err)
+	}
+
+	if _, err := s.JSONModuleArrAppend(ctx, "doc", "$.tags", "c"); !errors.Is(err, ErrModuleNotLoaded) {
+		t.Fatalf("JSONModuleArrAppend: %v, want ErrModuleNotLoaded", err)
+	}
+
+	if _, err := s.JSONModuleStrAppend(ctx, "doc", "$.name", "x"); !errors.Is(err, ErrModuleNotLoaded) {
+		t.Fatalf("JSONModuleStrAppend: %v, want ErrModuleNotLoaded", err)
+	}
+
+	if _, err := s.JSONModuleDel(ctx, "doc", "$.name"); !errors.Is(err, ErrModuleNotLoaded) {
+		t.Fatalf("JSONModuleDel: %v, want ErrModuleNotLoaded", err)
+	}
+
+	// Unknown capabilities leave the decision to the server, whose reply is translated.
+	err := moduleError(redisJSONModule, errors.New("ERR unknown command 'JSON.NUMINCRBY', with args beginning with: "))
+	if !errors.Is(err, ErrModuleNotLoaded) {
+		t.Fatalf("moduleError: %v, want ErrModuleNotLoaded", err)
+	}
+}
@@ -0,0 +1,58 @@
+package earedis
+
+import (
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+type (
+	LCSMatch           = rdb.LCSMatch
+	LCSMatchedPosition = rdb.LCSMatchedPosition
+	LCSPosition        = rdb.LCSPosition
+)
+
+// LCSOptions — the modifiers of the LCS command.
+type LCSOptions struct {
+	// Len — return only the length of the match (LCSMatch.Len).
+	Len bool
+	// Idx — return the matched ranges of both strings (LCSMatch.Matches) instead of the match string.
+	Idx bool
+	// MinMatchLen — with Idx, skip ranges shorter than this.
+	MinMatchLen int
+	// WithMatchLen — with Idx, report the length of every range.
+	WithMatchLen bool
+}
+
+// LCS — computes the longest common subsequence of the strings at key1 and key2. Requires Redis 7.0+.
+// Without options LCSMatch.MatchString holds the subsequence itself.
+func (s *Service) LCS(ctx *eactx.Context, key1, key2 string, opts LCSOptions) (match *LCSMatch, err error) {
+	defer s.reportReturned("LCS", "", &err)
+
+	if err := s.checkCommand("LCS"); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireVersion("LCS", 7, 0); err != nil {
+		return nil, err
+	}
+
+	prefix, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.LCS(ctx.GetContext(), &rdb.LCSQuery{
+		Key1:         prefix + key1,
+		Key2:         prefix + key2,
+		Len:          opts.Len,
+		Idx:          opts.Idx,
+		MinMatchLen:  opts.MinMatchLen,
+		WithMatchLen: opts.WithMatchLen,
+	}).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to compare keys", key1, key2, err)
+		return nil, err
+	}
+
+	return result, nil
+}
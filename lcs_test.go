@@ -0,0 +1,45 @@
+package earedis
+
+import "testing"
+
+func TestLCS(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "key1", "ohmytext", 0); err != nil {
+		t.Fatalf("Set key1: %v", err)
+	}
+
+	if err := s.Set(ctx, "key2", "mynewtext", 0); err != nil {
+		t.Fatalf("Set key2: %v", err)
+	}
+
+	match, err := s.LCS(ctx, "key1", "key2", LCSOptions{})
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("LCS: %v", err)
+	}
+
+	if match.MatchString != "mytext" {
+		t.Fatalf("LCS = %q, want mytext", match.MatchString)
+	}
+
+	match, err = s.LCS(ctx, "key1", "key2", LCSOptions{Len: true})
+	if err != nil || match.Len != 6 {
+		t.Fatalf("LCS LEN = %v, %v; want 6", match, err)
+	}
+
+	match, err = s.LCS(ctx, "key1", "key2", LCSOptions{Idx: true, MinMatchLen: 4, WithMatchLen: true})
+	if err != nil {
+		t.Fatalf("LCS IDX: %v", err)
+	}
+
+	if match.Len != 6 || len(match.Matches) != 1 {
+		t.Fatalf("LCS IDX = %+v, want one range of a length 6 match", match)
+	}
+
+	got := match.Matches[0]
+	if got.Key1 != (LCSPosition{Start: 4, End: 7}) || got.Key2 != (LCSPosition{Start: 5, End: 8}) || got.MatchLen != 4 {
+		t.Fatalf("LCS IDX range = %+v, want key1 4-7, key2 5-8, length 4", got)
+	}
+}
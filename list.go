@@ -0,0 +1,54 @@
+package earedis
+
+import (
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+// pushCappedScript — LPUSHes ARGV[1] to KEYS[1], trims the list to ARGV[2] elements and returns the trimmed tail.
+var pushCappedScript = rdb.NewScript(`
+redis.call('LPUSH', KEYS[1], ARGV[1])
+
+local maxLen = tonumber(ARGV[2])
+local evicted = redis.call('LRANGE', KEYS[1], maxLen, -1)
+if maxLen == 0 then
+	redis.call('DEL', KEYS[1])
+elseif #evicted > 0 then
+	redis.call('LTRIM', KEYS[1], 0, maxLen - 1)
+end
+
+return evicted
+`)
+
+// PushCapped — atomically prepends value to the list at key and trims it to the maxLen newest elements,
+// returning the elements that fell off the end, oldest last. A maxLen of 0 keeps nothing and evicts value too;
+// a negative maxLen is rejected.
+func (s *Service) PushCapped(ctx *eactx.Context, key string, value interface{}, maxLen int64) (evicted []string, err error) {
+	defer s.reportReturned("PushCapped", key, &err)
+
+	if err := s.checkCommand("EVAL"); err != nil {
+		return nil, err
+	}
+
+	if maxLen < 0 {
+		return nil, fmt.Errorf("earedis: invalid list cap %d", maxLen)
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkValueSize(key, value); err != nil {
+		return nil, err
+	}
+
+	evicted, err = pushCappedScript.Run(ctx.GetContext(), s.client, []string{key}, value, maxLen).StringSlice()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to push to capped list at key", key, err)
+		return nil, err
+	}
+
+	return evicted, nil
+}
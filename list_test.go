@@ -0,0 +1,51 @@
+package earedis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPushCappedEvictsTail(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	for i := 1; i <= 3; i++ {
+		evicted, err := s.PushCapped(ctx, "events", fmt.Sprint("e", i), 3)
+		if err != nil || len(evicted) != 0 {
+			t.Fatalf("PushCapped e%d = %v, %v; want nothing evicted", i, evicted, err)
+		}
+	}
+
+	evicted, err := s.PushCapped(ctx, "events", "e4", 3)
+	if err != nil || !reflect.DeepEqual(evicted, []string{"e1"}) {
+		t.Fatalf("PushCapped past the cap = %v, %v; want [e1]", evicted, err)
+	}
+
+	// Shrinking the cap evicts several elements at once, oldest last.
+	evicted, err = s.PushCapped(ctx, "events", "e5", 2)
+	if err != nil || !reflect.DeepEqual(evicted, []string{"e3", "e2"}) {
+		t.Fatalf("PushCapped with a smaller cap = %v, %v; want [e3 e2]", evicted, err)
+	}
+
+	if got := s.client.LRange(ctx.GetContext(), "events", 0, -1).Val(); !reflect.DeepEqual(got, []string{"e5", "e4"}) {
+		t.Fatalf("list = %v, want [e5 e4]", got)
+	}
+
+	evicted, err = s.PushCapped(ctx, "events", "e6", 0)
+	if err != nil || !reflect.DeepEqual(evicted, []string{"e6", "e5", "e4"}) {
+		t.Fatalf("PushCapped with cap 0 = %v, %v; want [e6 e5 e4]", evicted, err)
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "events").Val(); n != 0 {
+		t.Fatal("cap 0 left the list behind")
+	}
+
+	if _, err := s.PushCapped(ctx, "events", "e7", -1); err == nil {
+		t.Fatal("PushCapped accepted a negative cap")
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "events").Val(); n != 0 {
+		t.Fatal("a rejected push created the list")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/eris-apple/ealogger"
 	rdb "github.com/redis/go-redis/v9"
 	"reflect"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,6 +21,80 @@ type ConnectConfig struct {
 	Password          string
 	DB                int
 	pingConnectionTTL *time.Duration
+
+	// ClusterAddrs — seed node addresses; when set, the service connects in cluster mode and Addr and DB are ignored.
+	ClusterAddrs []string
+
+	// BlockingPoolSize — connections of the separate pool serving blocking commands (BLPOP, XREAD BLOCK),
+	// per node in cluster mode. Defaults to 4.
+	BlockingPoolSize int
+
+	// Codec — serializes the values of typed helpers such as Map. Defaults to JSONCodec.
+	Codec Codec
+
+	// ChannelPrefix — namespace prepended to pub/sub channel names and patterns by Publish, Subscribe and PSubscribe,
+	// isolating services that share a server.
+	ChannelPrefix string
+	// UnprefixedChannels — channels exempt from ChannelPrefix, e.g. broadcasts shared by every tenant.
+	UnprefixedChannels []string
+
+	// DisallowedCommands — redis commands (e.g. "FLUSHDB", "DEL") the service refuses to send.
+	// Methods check the command they issue; scripts are checked as "EVAL". Listing a command such as "CONFIG"
+	// also disables its subcommands, while "CONFIG SET" disables only that subcommand.
+	DisallowedCommands []string
+	// AllowAdminCommands — opts in to the commands that change the server itself (ConfigSet, SlowLogReset),
+	// which are refused with ErrCommandDisabled by default.
+	AllowAdminCommands bool
+
+	// MaxValueSize — the largest value in bytes that writes accept before returning ErrValueTooLarge; 0 disables the check.
+	MaxValueSize int
+
+	// KeyBreaker — the per-key circuit breaker guarding Get, JSONGet and Set; disabled by default.
+	KeyBreaker KeyBreakerConfig
+
+	// TenantExtractor — returns the tenant of a request context. When set, keys, key patterns and channels are
+	// namespaced with "<tenant>:", including the child keys dereferenced from set members by SMembersWithChild.
+	// Operations that cannot be namespaced (ResilientPipeline, BlockingClient, FlushDB, SPublish and SSubscribe)
+	// fail with ErrNotNamespaced instead, so raw commands cannot reach other tenants' keys. Tenants containing ":"
+	// or a glob character (*?[]\) are rejected with ErrInvalidTenant.
+	TenantExtractor func(ctx context.Context) (string, bool)
+	// AllowMissingTenant — when true, operations whose context carries no tenant run without a namespace
+	// instead of failing with ErrNoTenant.
+	AllowMissingTenant bool
+
+	// EncodingAdvisoryRate — the fraction (0..1) of writes to sets and hashes followed by an OBJECT ENCODING check
+	// that warns when the collection has outgrown its compact listpack/intset encoding. 0 disables the advisory.
+	EncodingAdvisoryRate float64
+
+	// InvalidationChannel — when set, Set, Del and Unlink broadcast the written keys on this channel,
+	// and StartInvalidationListener evicts the keys written by other instances.
+	InvalidationChannel string
+	// InstanceID — identifies this instance in invalidation broadcasts. Defaults to a random ID.
+	InstanceID string
+
+	// MaxPipelineSize — the most commands sent in one pipeline by ResilientPipeline and by multi-key helpers
+	// such as DelReport and MigrateCodec; longer batches are split and sent sequentially. 0 means no limit. Transactions
+	// (MULTI/EXEC) are never split, since that would break their atomicity.
+	MaxPipelineSize int
+
+	// StrictExport — when true, ExportHash and ExportSet return ErrNotFound for an empty or missing key.
+	StrictExport bool
+
+	// OnError — when set, called whenever a Service method returns an error, with the method name (e.g. "Get",
+	// "Map.Get", "ResilientPipeline.Exec"), the key it was called with ("" for methods on several keys or none)
+	// and the error, including rejections by DisallowedCommands or ReadOnly. Values are never passed. Misses
+	// (ErrNotFound, redis.Nil) and errors a method handles itself (fallbacks, skipped members, ...) are not
+	// reported. The callback runs on a single goroutine fed by a queue of 1024 reports, so it may block for
+	// a while; reports arriving while the queue is full are dropped, logged and counted in DroppedErrorReports.
+	OnError func(method, key string, err error)
+
+	// ReadOnly — when true, every method that writes (Set, Del, SAdd, ExpireCohort, FlushDB, Lua scripts, ...)
+	// returns ErrReadOnlyMode without contacting redis; reads work normally.
+	ReadOnly bool
+
+	// Clock — the source of the current time for TTL and time-window logic (leases, stale-while-revalidate,
+	// the key breaker, ...); defaults to the real clock. Replace it with a fake one to test such logic.
+	Clock Clock
 }
 
 // Service — redis service.
@@ -27,61 +102,167 @@ type Service struct {
 	l *ealogger.Logger
 	c *ConnectConfig
 
-	client *Client
+	client   rdb.UniversalClient
+	blocking rdb.UniversalClient
+	clock    Clock
+
+	disallowed map[string]struct{}
+	unprefixed map[string]struct{}
+	breaker    *keyBreaker
+	flights    *flightGroup
+	reporter   *errorReporter
+
+	instanceID string
+
+	capabilities ServerCapabilities
 
 	traceName string
 }
 
 // Init — initializing the connection with redis.
-func (s *Service) Init() error {
-	s.client = rdb.NewClient(&rdb.Options{
-		Addr:     s.c.Addr,
-		Username: s.c.User,
-		Password: s.c.Password,
-		DB:       s.c.DB,
-	})
+func (s *Service) Init() (err error) {
+	defer s.reportReturned("Init", "", &err)
+
+	s.client = s.newClient(0)
+	s.blocking = s.newClient(s.c.BlockingPoolSize)
 
+	// Context deadlines run on real time, so the ping timeout does not follow ConnectConfig.Clock.
 	ctx := eactx.NewContextWithTimeout(context.Background(), *s.c.pingConnectionTTL)
 	if err := s.client.Ping(ctx.GetContext()).Err(); err != nil {
 		s.l.ErrorT(s.traceName, "Failed to connect to redis", err)
 		return err
 	}
 
-	s.l.InfoT(s.traceName, "Successfully connected to redis")
+	capabilities, err := s.probeCapabilities(ctx)
+	if err != nil {
+		s.l.WarnT(s.traceName, "Failed to probe server capabilities", err)
+	}
+	s.capabilities = capabilities
+
+	s.l.InfoT(s.traceName, "Successfully connected to redis", s.capabilities.Version)
 	return nil
 }
 
+// newClient — creates a standalone or cluster client for the config; poolSize 0 keeps the go-redis default.
+func (s *Service) newClient(poolSize int) rdb.UniversalClient {
+	var client rdb.UniversalClient
+	if len(s.c.ClusterAddrs) > 0 {
+		client = rdb.NewClusterClient(&rdb.ClusterOptions{
+			Addrs:    s.c.ClusterAddrs,
+			Username: s.c.User,
+			Password: s.c.Password,
+			PoolSize: poolSize,
+		})
+	} else {
+		client = rdb.NewClient(&rdb.Options{
+			Addr:     s.c.Addr,
+			Username: s.c.User,
+			Password: s.c.Password,
+			DB:       s.c.DB,
+			PoolSize: poolSize,
+		})
+	}
+
+	return client
+}
+
 // Disconnect — disconnecting from redis.
-func (s *Service) Disconnect() error {
+func (s *Service) Disconnect() (err error) {
+	defer s.reportReturned("Disconnect", "", &err)
+
 	if err := s.client.Close(); err != nil {
 		s.l.ErrorT(s.traceName, "Failed to disconnect from redis", err)
 		return err
 	}
 
+	if err := s.blocking.Close(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to disconnect blocking client from redis", err)
+		return err
+	}
+
+	if s.reporter != nil {
+		s.reporter.close()
+	}
+
 	s.l.InfoT(s.traceName, "Successfully disconnected to redis")
 	s.client = nil
+	s.blocking = nil
 	return nil
 }
 
-func (s *Service) Set(ctx *eactx.Context, key string, value interface{}, expiration time.Duration) error {
-	if err := s.client.Set(ctx.GetContext(), key, value, expiration).Err(); err != nil {
+func (s *Service) Set(ctx *eactx.Context, key string, value interface{}, expiration time.Duration) (err error) {
+	defer s.reportReturned("Set", key, &err)
+
+	if err := s.checkCommand("SET"); err != nil {
+		return err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return s.set(ctx, key, value, expiration)
+}
+
+// set — Set for an already namespaced key, after checkCommand("SET").
+func (s *Service) set(ctx *eactx.Context, key string, value interface{}, expiration time.Duration) error {
+	if err := s.checkValueSize(key, value); err != nil {
+		return err
+	}
+
+	if err := s.checkKey(key); err != nil {
+		return err
+	}
+
+	err := s.client.Set(ctx.GetContext(), key, value, expiration).Err()
+	s.recordKey(key, err)
+	if err != nil {
 		s.l.ErrorT(s.traceName, "Failed to set key", key, err)
 		return err
 	}
 
+	s.publishInvalidation(ctx, key)
 	return nil
 }
 
-func (s *Service) SAdd(ctx *eactx.Context, key string, members ...interface{}) error {
+func (s *Service) SAdd(ctx *eactx.Context, key string, members ...interface{}) (err error) {
+	defer s.reportReturned("SAdd", key, &err)
+
+	if err := s.checkCommand("SADD"); err != nil {
+		return err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkValueSize(key, members...); err != nil {
+		return err
+	}
+
 	if err := s.client.SAdd(ctx.GetContext(), key, members).Err(); err != nil {
 		s.l.ErrorT(s.traceName, "Failed to set members at key", key, err)
 		return err
 	}
 
+	s.adviseEncoding(ctx, key)
 	return nil
 }
 
-func (s *Service) SMembers(ctx *eactx.Context, key string) ([]string, error) {
+func (s *Service) SMembers(ctx *eactx.Context, key string) (members []string, err error) {
+	defer s.reportReturned("SMembers", key, &err)
+
+	if err := s.checkCommand("SMEMBERS"); err != nil {
+		return nil, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := s.client.SMembers(ctx.GetContext(), key).Result()
 	if err != nil {
 		s.l.ErrorT(s.traceName, "Failed to set members at key", key, err)
@@ -91,7 +272,23 @@ func (s *Service) SMembers(ctx *eactx.Context, key string) ([]string, error) {
 	return result, nil
 }
 
-func (s *Service) SMembersWithChild(ctx *eactx.Context, key string) ([]string, error) {
+func (s *Service) SMembersWithChild(ctx *eactx.Context, key string) (result []string, err error) {
+	defer s.reportReturned("SMembersWithChild", key, &err)
+
+	return s.sMembersWithChild(ctx, key)
+}
+
+// sMembersWithChild — SMembersWithChild without error reporting, for methods building on it.
+func (s *Service) sMembersWithChild(ctx *eactx.Context, key string) ([]string, error) {
+	if err := s.checkCommand("SMEMBERS"); err != nil {
+		return nil, err
+	}
+
+	key, err := s.tenantKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
 	s.l.InfoT(s.traceName, "Get members child by key ", key)
 	members, err := s.client.SMembers(ctx.GetContext(), key).Result()
 	if err != nil {
@@ -101,7 +298,7 @@ func (s *Service) SMembersWithChild(ctx *eactx.Context, key string) ([]string, e
 
 	result := make([]string, 0)
 	for _, member := range members {
-		v, err := s.Get(ctx, member)
+		v, err := s.get(ctx, member)
 		if err != nil || len(v) == 0 {
 			s.l.ErrorT(s.traceName, "Failed to get member", member, err)
 			continue
@@ -113,32 +310,77 @@ func (s *Service) SMembersWithChild(ctx *eactx.Context, key string) ([]string, e
 	return result, nil
 }
 
-func (s *Service) JSONSMembersWithChild(ctx *eactx.Context, key string, v interface{}) error {
-	result, err := s.SMembersWithChild(ctx, key)
+// JSONSMembersWithChild — decodes the values of the set members at key into the slice pointed to by v.
+// Members that fail to decode are logged and skipped, like missing members in SMembersWithChild.
+func (s *Service) JSONSMembersWithChild(ctx *eactx.Context, key string, v interface{}) (err error) {
+	defer s.reportReturned("JSONSMembersWithChild", key, &err)
+
+	_, err = s.jsonSMembersWithChildReport(ctx, key, v)
+	return err
+}
+
+// JSONSMembersWithChildReport — same as JSONSMembersWithChild, additionally returning the skipped per-member decode failures.
+func (s *Service) JSONSMembersWithChildReport(ctx *eactx.Context, key string, v interface{}) (decodeErrs []error, err error) {
+	defer s.reportReturned("JSONSMembersWithChildReport", key, &err)
+
+	return s.jsonSMembersWithChildReport(ctx, key, v)
+}
+
+// jsonSMembersWithChildReport — JSONSMembersWithChildReport without error reporting.
+func (s *Service) jsonSMembersWithChildReport(ctx *eactx.Context, key string, v interface{}) ([]error, error) {
+	if err := checkSliceTarget(v); err != nil {
+		return nil, err
+	}
+
+	result, err := s.sMembersWithChild(ctx, key)
 	if err != nil {
 		s.l.ErrorT(s.traceName, "Failed to set members at key", key, err)
-		return err
+		return nil, err
 	}
 
 	sliceValue := reflect.ValueOf(v).Elem()
 	elemType := sliceValue.Type().Elem()
 
-	for _, item := range result {
+	var decodeErrs []error
+	for i, item := range result {
 		newElem := reflect.New(elemType).Elem()
 
 		err := json.Unmarshal([]byte(item), newElem.Addr().Interface())
 		if err != nil {
-			return err
+			s.l.ErrorT(s.traceName, "Failed to decode member at key", key, err)
+			decodeErrs = append(decodeErrs, fmt.Errorf("earedis: decode member %d of %s: %w", i, key, err))
+			continue
 		}
 
 		sliceValue.Set(reflect.Append(sliceValue, newElem))
 	}
 
-	return nil
+	return decodeErrs, nil
+}
+
+func (s *Service) Get(ctx *eactx.Context, key string) (value string, err error) {
+	defer s.reportReturned("Get", key, &err)
+
+	return s.get(ctx, key)
 }
 
-func (s *Service) Get(ctx *eactx.Context, key string) (string, error) {
+// get — Get without error reporting, for methods that handle a failed member themselves.
+func (s *Service) get(ctx *eactx.Context, key string) (string, error) {
+	if err := s.checkCommand("GET"); err != nil {
+		return "", err
+	}
+
+	key, err := s.tenantKey(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkKey(key); err != nil {
+		return "", err
+	}
+
 	result, err := s.client.Get(ctx.GetContext(), key).Result()
+	s.recordKey(key, err)
 	if err != nil || len(result) == 0 {
 		s.l.ErrorT(s.traceName, "Failed to get key", key, err)
 		return "", err
@@ -147,8 +389,28 @@ func (s *Service) Get(ctx *eactx.Context, key string) (string, error) {
 	return result, nil
 }
 
-func (s *Service) JSONGet(ctx *eactx.Context, key string, v interface{}) error {
+func (s *Service) JSONGet(ctx *eactx.Context, key string, v interface{}) (err error) {
+	defer s.reportReturned("JSONGet", key, &err)
+
+	if err := checkTarget(v); err != nil {
+		return err
+	}
+
+	if err := s.checkCommand("GET"); err != nil {
+		return err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkKey(key); err != nil {
+		return err
+	}
+
 	result, err := s.client.Get(ctx.GetContext(), key).Result()
+	s.recordKey(key, err)
 	if err != nil || len(result) == 0 {
 		s.l.ErrorT(s.traceName, "Failed to get key", key, err)
 		return err
@@ -161,7 +423,18 @@ func (s *Service) JSONGet(ctx *eactx.Context, key string, v interface{}) error {
 	return nil
 }
 
-func (s *Service) MGet(ctx *eactx.Context, key ...string) ([]interface{}, error) {
+func (s *Service) MGet(ctx *eactx.Context, key ...string) (values []interface{}, err error) {
+	defer s.reportReturned("MGet", "", &err)
+
+	if err := s.checkCommand("MGET"); err != nil {
+		return nil, err
+	}
+
+	key, err = s.tenantKeys(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := s.client.MGet(ctx.GetContext(), key...).Result()
 	if err != nil {
 		s.l.ErrorT(s.traceName, "Failed to get key", key, err)
@@ -171,26 +444,193 @@ func (s *Service) MGet(ctx *eactx.Context, key ...string) ([]interface{}, error)
 	return result, nil
 }
 
-func (s *Service) Del(ctx *eactx.Context, keys ...string) error {
-	if err := s.client.Del(ctx.GetContext(), keys...).Err(); err != nil {
+// MGetChunked — fetches many keys with MGETs of at most chunkSize keys, running up to concurrency of them
+// at a time, and returns the values in the order of keys (nil for missing keys). In cluster mode keys are
+// grouped by hash slot first, so no chunk fails with CROSSSLOT. The first failing chunk cancels the rest.
+func (s *Service) MGetChunked(ctx *eactx.Context, keys []string, chunkSize int, concurrency int) (values []interface{}, err error) {
+	defer s.reportReturned("MGetChunked", "", &err)
+
+	if err := s.checkCommand("MGET"); err != nil {
+		return nil, err
+	}
+
+	keys, err = s.tenantKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, len(keys))
+	err = s.runChunks(ctx, s.chunkKeys(keys, chunkSize), concurrency, true, func(ctx context.Context, chunk keyChunk) error {
+		values, err := s.client.MGet(ctx, chunk.keys...).Result()
+		if err != nil {
+			return err
+		}
+
+		// Every chunk writes a disjoint set of positions.
+		for i, value := range values {
+			result[chunk.indexes[i]] = value
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get keys in chunks", len(keys), err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Del — deletes keys and returns how many existed. In cluster mode keys are grouped by hash slot and
+// every group is deleted with its own command, concurrently, so keys may span slots; the counts of the
+// groups are summed and the errors of failed groups joined.
+func (s *Service) Del(ctx *eactx.Context, keys ...string) (deleted int64, err error) {
+	defer s.reportReturned("Del", "", &err)
+
+	return s.deleteKeys(ctx, "DEL", keys)
+}
+
+// Unlink — the Del twin that reclaims the memory of the deleted keys in the background.
+func (s *Service) Unlink(ctx *eactx.Context, keys ...string) (deleted int64, err error) {
+	defer s.reportReturned("Unlink", "", &err)
+
+	return s.deleteKeys(ctx, "UNLINK", keys)
+}
+
+// DelReport — deletes keys and returns the ones that existed, in the order given.
+// It sends one DEL per key in a pipeline, so it costs a command per key where Del sends a single one
+// (or one per slot in cluster mode); prefer Del when the count is enough.
+func (s *Service) DelReport(ctx *eactx.Context, keys ...string) (removed []string, err error) {
+	defer s.reportReturned("DelReport", "", &err)
+
+	if err := s.checkCommand("DEL"); err != nil {
+		return nil, err
+	}
+
+	tenantKeys, err := s.tenantKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	dels := make([]*rdb.IntCmd, len(tenantKeys))
+	err = s.pipelined(ctx.GetContext(), s.client, len(tenantKeys), func(pipe rdb.Pipeliner, i int) {
+		dels[i] = pipe.Del(ctx.GetContext(), tenantKeys[i])
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to delete keys", tenantKeys, err)
+		return nil, err
+	}
+
+	removed = make([]string, 0)
+	removedKeys := make([]string, 0)
+	for i, cmd := range dels {
+		if cmd.Val() > 0 {
+			removed = append(removed, keys[i])
+			removedKeys = append(removedKeys, tenantKeys[i])
+		}
+	}
+
+	s.publishInvalidation(ctx, removedKeys...)
+	return removed, nil
+}
+
+func (s *Service) deleteKeys(ctx *eactx.Context, command string, keys []string) (int64, error) {
+	if err := s.checkCommand(command); err != nil {
+		return 0, err
+	}
+
+	keys, err := s.tenantKeys(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted atomic.Int64
+	err = s.runChunks(ctx, s.chunkKeys(keys, 0), deleteConcurrency, false, func(ctx context.Context, chunk keyChunk) error {
+		cmd := s.client.Del
+		if command == "UNLINK" {
+			cmd = s.client.Unlink
+		}
+
+		n, err := cmd(ctx, chunk.keys...).Result()
+		deleted.Add(n)
+		return err
+	})
+	if err != nil {
 		s.l.ErrorT(s.traceName, "Failed to delete keys", keys, err)
+		return deleted.Load(), err
+	}
+
+	s.publishInvalidation(ctx, keys...)
+	return deleted.Load(), nil
+}
+
+// FlushDB — removes every key of the selected database. The keys of every tenant would go with it,
+// so FlushDB returns ErrNotNamespaced while ConnectConfig.TenantExtractor is set.
+func (s *Service) FlushDB(ctx *eactx.Context) (err error) {
+	defer s.reportReturned("FlushDB", "", &err)
+
+	if err := s.requireNamespace("FlushDB"); err != nil {
+		return err
+	}
+
+	if err := s.checkCommand("FLUSHDB"); err != nil {
+		return err
+	}
+
+	if err := s.client.FlushDB(ctx.GetContext()).Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to flush database", err)
 		return err
 	}
 
+	s.l.WarnT(s.traceName, "Flushed database")
 	return nil
 }
 
 // NewService — returns the Service instance.
 func NewService(l *ealogger.Logger, c *ConnectConfig, traceName string) *Service {
+	if c.BlockingPoolSize <= 0 {
+		c.BlockingPoolSize = defaultBlockingPoolSize
+	}
+
+	if c.Codec == nil {
+		c.Codec = JSONCodec{}
+	}
+
 	if c.pingConnectionTTL == nil {
 		defaultPingConnectionTTL := 30 * time.Second
 		c.pingConnectionTTL = &defaultPingConnectionTTL
 	}
 
-	return &Service{
+	s := &Service{
 		l: l,
 		c: c,
 
+		clock: c.Clock,
+
+		disallowed: newCommandSet(c.DisallowedCommands),
+		unprefixed: newSet(c.UnprefixedChannels),
+		flights:    &flightGroup{flights: make(map[string]*flight)},
+
+		instanceID: c.InstanceID,
+
 		traceName: fmt.Sprintf("[%s_RedisService]", traceName),
 	}
+
+	if s.clock == nil {
+		s.clock = realClock{}
+	}
+
+	if s.instanceID == "" {
+		s.instanceID, _ = newToken()
+	}
+
+	if c.KeyBreaker.Threshold > 0 {
+		s.breaker = newKeyBreaker(c.KeyBreaker, func() Clock { return s.clock })
+	}
+
+	if c.OnError != nil {
+		s.reporter = newErrorReporter(c.OnError, l, s.traceName)
+	}
+
+	return s
 }
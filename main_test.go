@@ -0,0 +1,317 @@
+package earedis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	"github.com/eris-apple/ealogger"
+	rdb "github.com/redis/go-redis/v9"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testAddrEnv — names a disposable redis server for the integration tests, which are skipped when it is unset.
+// The database is flushed before and after every test.
+const testAddrEnv = "EAREDIS_TEST_ADDR"
+
+// testClusterAddrsEnv — comma-separated seed nodes of a disposable redis cluster for the cluster tests.
+const testClusterAddrsEnv = "EAREDIS_TEST_CLUSTER_ADDRS"
+
+var testLogger = ealogger.NewDefaultLogger(ealogger.ProdMode)
+
+// newTestService — connects a service to the test server, applying configure to its config first.
+func newTestService(t *testing.T, configure ...func(c *ConnectConfig)) *Service {
+	t.Helper()
+
+	addr := os.Getenv(testAddrEnv)
+	if addr == "" {
+		t.Skipf("%s is not set", testAddrEnv)
+	}
+
+	return startTestService(t, &ConnectConfig{Addr: addr}, configure)
+}
+
+// newTestClusterService — like newTestService, but connects to the test cluster.
+func newTestClusterService(t *testing.T, configure ...func(c *ConnectConfig)) *Service {
+	t.Helper()
+
+	addrs := os.Getenv(testClusterAddrsEnv)
+	if addrs == "" {
+		t.Skipf("%s is not set", testClusterAddrsEnv)
+	}
+
+	return startTestService(t, &ConnectConfig{ClusterAddrs: strings.Split(addrs, ",")}, configure)
+}
+
+func startTestService(t *testing.T, c *ConnectConfig, configure []func(c *ConnectConfig)) *Service {
+	t.Helper()
+
+	for _, f := range configure {
+		f(c)
+	}
+
+	s := NewService(testLogger, c, "test")
+	if err := s.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	// Flushed through the raw client, so tests that disallow FLUSHDB or run read-only still start clean.
+	flush := func() {
+		err := s.forEachNode(context.Background(), func(ctx context.Context, client rdb.Cmdable) error {
+			return client.FlushDB(ctx).Err()
+		})
+		if err != nil {
+			t.Errorf("FLUSHDB: %v", err)
+		}
+	}
+
+	flush()
+	t.Cleanup(func() {
+		flush()
+		_ = s.Disconnect()
+	})
+
+	return s
+}
+
+// captureLogger — returns a logger whose console output is collected, and a function that stops the collection
+// and returns everything logged so far.
+func captureLogger(t *testing.T) (*ealogger.Logger, func() string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+
+	// The logger binds os.Stdout when it is created.
+	stdout := os.Stdout
+	os.Stdout = w
+	l := ealogger.NewDefaultLogger(ealogger.ProdMode)
+	os.Stdout = stdout
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(&out, r)
+	}()
+
+	var once sync.Once
+	stop := func() string {
+		once.Do(func() {
+			_ = w.Close()
+			<-done
+			_ = r.Close()
+		})
+
+		return out.String()
+	}
+	t.Cleanup(func() { stop() })
+
+	return l, stop
+}
+
+// skipUnsupported — skips the test when the server does not implement a command, e.g. an older server
+// or one without the module under test.
+func skipUnsupported(t *testing.T, err error) {
+	t.Helper()
+
+	if err == nil {
+		return
+	}
+
+	msg := strings.ToLower(err.Error())
+	if errors.Is(err, ErrUnsupported) || errors.Is(err, ErrModuleNotLoaded) ||
+		strings.Contains(msg, "unknown command") || strings.Contains(msg, "unknown subcommand") {
+		t.Skipf("unsupported by the test server: %v", err)
+	}
+}
+
+// testContext — returns a context cancelled when the test ends.
+func testContext(t *testing.T) *eactx.Context {
+	ctx := eactx.NewContextWithCancel(context.Background())
+	t.Cleanup(ctx.Cancel)
+	return ctx
+}
+
+// commandKey — returns the first argument of a keyed command such as GET or SET, for hooks matching
+// commands by key; "" for commands without arguments.
+func commandKey(cmd rdb.Cmder) string {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return ""
+	}
+
+	key, _ := args[1].(string)
+	return key
+}
+
+func TestJSONSMembersWithChildReportSkipsMalformed(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	children := map[string]string{
+		"child:1": `{"name":"a"}`,
+		"child:2": `not json`,
+		"child:3": `{"name":"c"}`,
+	}
+	for key, value := range children {
+		if err := s.Set(ctx, key, value, 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	if err := s.SAdd(ctx, "parent", "child:1", "child:2", "child:3"); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+
+	type child struct {
+		Name string `json:"name"`
+	}
+
+	var got []child
+	decodeErrs, err := s.JSONSMembersWithChildReport(ctx, "parent", &got)
+	if err != nil {
+		t.Fatalf("JSONSMembersWithChildReport: %v", err)
+	}
+
+	if len(decodeErrs) != 1 {
+		t.Fatalf("got %d decode errors, want 1: %v", len(decodeErrs), decodeErrs)
+	}
+
+	names := make(map[string]bool)
+	for _, c := range got {
+		names[c.Name] = true
+	}
+
+	if len(got) != 2 || !names["a"] || !names["c"] {
+		t.Fatalf("decoded %v, want a and c", got)
+	}
+}
+
+func TestMGetChunkedOrder(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	keys := make([]string, 5000)
+	values := make([]interface{}, 0, len(keys))
+	for i := range keys {
+		keys[i] = fmt.Sprint("key-", i)
+
+		// Every third key stays missing.
+		if i%3 != 0 {
+			values = append(values, keys[i], fmt.Sprint("value-", i))
+		}
+	}
+
+	if err := s.client.MSet(ctx.GetContext(), values...).Err(); err != nil {
+		t.Fatalf("MSET: %v", err)
+	}
+
+	got, err := s.MGetChunked(ctx, keys, 128, 8)
+	if err != nil {
+		t.Fatalf("MGetChunked: %v", err)
+	}
+
+	if len(got) != len(keys) {
+		t.Fatalf("MGetChunked returned %d values, want %d", len(got), len(keys))
+	}
+
+	for i, value := range got {
+		if i%3 == 0 {
+			if value != nil {
+				t.Fatalf("value %d = %v, want nil", i, value)
+			}
+			continue
+		}
+
+		if want := fmt.Sprint("value-", i); value != want {
+			t.Fatalf("value %d = %v, want %s", i, value, want)
+		}
+	}
+}
+
+// testDeleteSpanningSlots — deletes keys spread over many hash slots, with some of them missing.
+func testDeleteSpanningSlots(t *testing.T, s *Service) {
+	ctx := testContext(t)
+
+	keys := make([]string, 200)
+	slots := make(map[int]struct{})
+	for i := range keys {
+		keys[i] = fmt.Sprint("key-", i)
+		slots[keySlot(keys[i])] = struct{}{}
+
+		if i%4 != 0 {
+			if err := s.Set(ctx, keys[i], "value", 0); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+		}
+	}
+
+	if len(slots) < 2 {
+		t.Fatal("the keys share a slot")
+	}
+
+	deleted, err := s.Del(ctx, keys[:100]...)
+	if err != nil || deleted != 75 {
+		t.Fatalf("Del = %d, %v; want 75", deleted, err)
+	}
+
+	unlinked, err := s.Unlink(ctx, keys[100:]...)
+	if err != nil || unlinked != 75 {
+		t.Fatalf("Unlink = %d, %v; want 75", unlinked, err)
+	}
+
+	for _, key := range keys {
+		if _, err := s.Get(ctx, key); !errors.Is(err, rdb.Nil) {
+			t.Fatalf("Get %s after delete: %v, want redis.Nil", key, err)
+		}
+	}
+}
+
+func TestDelSpanningSlotsStandalone(t *testing.T) {
+	testDeleteSpanningSlots(t, newTestService(t))
+}
+
+func TestDelSpanningSlotsCluster(t *testing.T) {
+	testDeleteSpanningSlots(t, newTestClusterService(t))
+}
+
+func TestDelReportMixedKeys(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.TenantExtractor = tenantExtractor
+	})
+	ctx := tenantContext(t, "a")
+
+	for _, key := range []string{"k1", "k3", "k4"} {
+		if err := s.Set(ctx, key, "value", 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	removed, err := s.DelReport(ctx, "k1", "k2", "k3", "k5", "k4", "k1")
+	if err != nil {
+		t.Fatalf("DelReport: %v", err)
+	}
+
+	// Reported in the order given, without the tenant namespace; the repeated k1 is already gone.
+	if want := []string{"k1", "k3", "k4"}; !reflect.DeepEqual(removed, want) {
+		t.Fatalf("DelReport = %v, want %v", removed, want)
+	}
+
+	if n := s.client.Exists(ctx.GetContext(), "a:k1", "a:k3", "a:k4").Val(); n != 0 {
+		t.Fatalf("%d reported keys still exist", n)
+	}
+
+	removed, err = s.DelReport(ctx, "k1", "k2")
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("DelReport of absent keys = %v, %v; want none", removed, err)
+	}
+}
@@ -0,0 +1,192 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+const mapScanCount = 100
+
+// Map — a typed view of the redis hash at a key. Both map keys and values are encoded into hash fields
+// and values with ConnectConfig.Codec, so a key type must encode deterministically (e.g. ints, strings).
+type Map[K comparable, V any] struct {
+	s   *Service
+	key string
+}
+
+// NewMap — returns the Map bound to the hash at key.
+func NewMap[K comparable, V any](s *Service, key string) *Map[K, V] {
+	return &Map[K, V]{s: s, key: key}
+}
+
+// Set — stores v under k.
+func (m *Map[K, V]) Set(ctx *eactx.Context, k K, v V) (err error) {
+	defer m.s.reportReturned("Map.Set", m.key, &err)
+
+	if err := m.s.checkCommand("HSET"); err != nil {
+		return err
+	}
+
+	key, err := m.s.tenantKey(ctx, m.key)
+	if err != nil {
+		return err
+	}
+
+	field, err := m.s.c.Codec.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	value, err := m.s.c.Codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := m.s.checkValueSize(key, value); err != nil {
+		return err
+	}
+
+	if err := m.s.client.HSet(ctx.GetContext(), key, field, value).Err(); err != nil {
+		m.s.l.ErrorT(m.s.traceName, "Failed to set field at key", key, err)
+		return err
+	}
+
+	m.s.adviseEncoding(ctx, key)
+	return nil
+}
+
+// Get — returns the value under k; the boolean is false when k is not present.
+func (m *Map[K, V]) Get(ctx *eactx.Context, k K) (value V, ok bool, err error) {
+	defer m.s.reportReturned("Map.Get", m.key, &err)
+
+	var v V
+
+	if err := m.s.checkCommand("HGET"); err != nil {
+		return v, false, err
+	}
+
+	key, err := m.s.tenantKey(ctx, m.key)
+	if err != nil {
+		return v, false, err
+	}
+
+	field, err := m.s.c.Codec.Marshal(k)
+	if err != nil {
+		return v, false, err
+	}
+
+	data, err := m.s.client.HGet(ctx.GetContext(), key, string(field)).Bytes()
+	if errors.Is(err, rdb.Nil) {
+		return v, false, nil
+	}
+
+	if err != nil {
+		m.s.l.ErrorT(m.s.traceName, "Failed to get field at key", key, err)
+		return v, false, err
+	}
+
+	if err := m.s.c.Codec.Unmarshal(data, &v); err != nil {
+		m.s.l.ErrorT(m.s.traceName, "Failed to decode field at key", key, err)
+		return v, false, err
+	}
+
+	return v, true, nil
+}
+
+// Delete — removes k; deleting a missing key is not an error.
+func (m *Map[K, V]) Delete(ctx *eactx.Context, k K) (err error) {
+	defer m.s.reportReturned("Map.Delete", m.key, &err)
+
+	if err := m.s.checkCommand("HDEL"); err != nil {
+		return err
+	}
+
+	key, err := m.s.tenantKey(ctx, m.key)
+	if err != nil {
+		return err
+	}
+
+	field, err := m.s.c.Codec.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	if err := m.s.client.HDel(ctx.GetContext(), key, string(field)).Err(); err != nil {
+		m.s.l.ErrorT(m.s.traceName, "Failed to delete field at key", key, err)
+		return err
+	}
+
+	return nil
+}
+
+// Range — calls fn for every entry, HSCAN-iterating the hash, until fn returns false.
+// As with HSCAN, an entry may be visited more than once if the hash changes during the iteration.
+func (m *Map[K, V]) Range(ctx *eactx.Context, fn func(K, V) bool) (err error) {
+	defer m.s.reportReturned("Map.Range", m.key, &err)
+
+	if err := m.s.checkCommand("HSCAN"); err != nil {
+		return err
+	}
+
+	key, err := m.s.tenantKey(ctx, m.key)
+	if err != nil {
+		return err
+	}
+
+	var cursor uint64
+	for {
+		var values []string
+		values, cursor, err = m.s.client.HScan(ctx.GetContext(), key, cursor, "", mapScanCount).Result()
+		if err != nil {
+			m.s.l.ErrorT(m.s.traceName, "Failed to scan hash", key, err)
+			return err
+		}
+
+		for i := 0; i+1 < len(values); i += 2 {
+			var (
+				k K
+				v V
+			)
+
+			if err := m.s.c.Codec.Unmarshal([]byte(values[i]), &k); err != nil {
+				return fmt.Errorf("earedis: decode field of %s: %w", key, err)
+			}
+
+			if err := m.s.c.Codec.Unmarshal([]byte(values[i+1]), &v); err != nil {
+				return fmt.Errorf("earedis: decode value of %s: %w", key, err)
+			}
+
+			if !fn(k, v) {
+				return nil
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// Len — returns the number of entries.
+func (m *Map[K, V]) Len(ctx *eactx.Context) (n int64, err error) {
+	defer m.s.reportReturned("Map.Len", m.key, &err)
+
+	if err := m.s.checkCommand("HLEN"); err != nil {
+		return 0, err
+	}
+
+	key, err := m.s.tenantKey(ctx, m.key)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := m.s.client.HLen(ctx.GetContext(), key).Result()
+	if err != nil {
+		m.s.l.ErrorT(m.s.traceName, "Failed to get length of hash", key, err)
+		return 0, err
+	}
+
+	return result, nil
+}
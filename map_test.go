@@ -0,0 +1,69 @@
+package earedis
+
+import (
+	"testing"
+)
+
+type mapUser struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+func TestMapIntKeyStructValue(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+	users := NewMap[int, mapUser](s, "users")
+
+	want := map[int]mapUser{
+		1:  {Name: "alice", Roles: []string{"admin"}},
+		2:  {Name: "bob"},
+		42: {Name: "carol", Roles: []string{"dev", "ops"}},
+	}
+	for id, user := range want {
+		if err := users.Set(ctx, id, user); err != nil {
+			t.Fatalf("Set %d: %v", id, err)
+		}
+	}
+
+	got, ok, err := users.Get(ctx, 42)
+	if err != nil || !ok || got.Name != "carol" || len(got.Roles) != 2 || got.Roles[1] != "ops" {
+		t.Fatalf("Get 42 = %+v, %v, %v; want carol with two roles", got, ok, err)
+	}
+
+	if _, ok, err := users.Get(ctx, 7); err != nil || ok {
+		t.Fatalf("Get missing = %v, %v; want not present without error", ok, err)
+	}
+
+	if err := users.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	delete(want, 2)
+
+	if n, err := users.Len(ctx); err != nil || n != 2 {
+		t.Fatalf("Len = %d, %v; want 2", n, err)
+	}
+
+	seen := make(map[int]mapUser)
+	err = users.Range(ctx, func(id int, user mapUser) bool {
+		seen[id] = user
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Range: %v", err)
+	}
+
+	if len(seen) != len(want) {
+		t.Fatalf("Range visited %v, want %v", seen, want)
+	}
+
+	for id, user := range want {
+		if seen[id].Name != user.Name {
+			t.Fatalf("Range[%d] = %+v, want %+v", id, seen[id], user)
+		}
+	}
+
+	visited := 0
+	if err := users.Range(ctx, func(int, mapUser) bool { visited++; return false }); err != nil || visited != 1 {
+		t.Fatalf("stopped Range visited %d, %v; want 1", visited, err)
+	}
+}
@@ -0,0 +1,105 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+// MemoryStats — returns the MEMORY STATS report of the server, e.g. "total.allocated", "peak.allocated", "keys.count".
+// Values are as returned by the server: integers, floats (as strings in RESP2) and nested per-db reports.
+func (s *Service) MemoryStats(ctx *eactx.Context) (stats map[string]interface{}, err error) {
+	defer s.reportReturned("MemoryStats", "", &err)
+
+	if err := s.checkCommand("MEMORY STATS"); err != nil {
+		return nil, err
+	}
+
+	reply, err := s.client.Do(ctx.GetContext(), "memory", "stats").Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get memory stats", err)
+		return nil, err
+	}
+
+	result, ok := replyMap(reply)
+	if !ok {
+		err := fmt.Errorf("earedis: unexpected MEMORY STATS reply %T", reply)
+		s.l.ErrorT(s.traceName, "Failed to parse memory stats", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MemoryDoctor — returns the human-readable MEMORY DOCTOR advice of the server.
+func (s *Service) MemoryDoctor(ctx *eactx.Context) (report string, err error) {
+	defer s.reportReturned("MemoryDoctor", "", &err)
+
+	if err := s.checkCommand("MEMORY DOCTOR"); err != nil {
+		return "", err
+	}
+
+	result, err := s.client.Do(ctx.GetContext(), "memory", "doctor").Text()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get memory doctor report", err)
+		return "", err
+	}
+
+	return result, nil
+}
+
+// EstimateSetFootprint — estimates the memory used by the set at setKey together with the child keys
+// named by its members (see SMembersWithChild), using one pipeline of MEMORY USAGE.
+// perKey holds the bytes of the set and of every existing child; missing children are skipped.
+func (s *Service) EstimateSetFootprint(ctx *eactx.Context, setKey string) (total int64, perKey map[string]int64, err error) {
+	defer s.reportReturned("EstimateSetFootprint", setKey, &err)
+
+	if err := s.checkCommand("SMEMBERS"); err != nil {
+		return 0, nil, err
+	}
+
+	if err := s.checkCommand("MEMORY USAGE"); err != nil {
+		return 0, nil, err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	members, err := s.client.SMembers(ctx.GetContext(), tenant+setKey).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get members at key", setKey, err)
+		return 0, nil, err
+	}
+
+	keys := append([]string{setKey}, members...)
+	usages := make([]*rdb.IntCmd, len(keys))
+
+	err = s.pipelined(ctx.GetContext(), s.client, len(keys), func(pipe rdb.Pipeliner, i int) {
+		usages[i] = pipe.MemoryUsage(ctx.GetContext(), tenant+keys[i])
+	})
+	if err != nil && !errors.Is(err, rdb.Nil) {
+		s.l.ErrorT(s.traceName, "Failed to get memory usage of set", setKey, err)
+		return 0, nil, err
+	}
+
+	perKey = make(map[string]int64, len(keys))
+	for i, cmd := range usages {
+		usage, err := cmd.Result()
+		if errors.Is(err, rdb.Nil) {
+			continue
+		}
+
+		if err != nil {
+			s.l.ErrorT(s.traceName, "Failed to get memory usage of key", keys[i], err)
+			return 0, nil, err
+		}
+
+		perKey[keys[i]] = usage
+		total += usage
+	}
+
+	return total, perKey, nil
+}
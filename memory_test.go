@@ -0,0 +1,59 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemoryStatsTotalAllocated(t *testing.T) {
+	s := newTestService(t)
+
+	stats, err := s.MemoryStats(testContext(t))
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("MemoryStats: %v", err)
+	}
+
+	if _, ok := stats["total.allocated"]; !ok {
+		t.Fatalf("MemoryStats has no total.allocated: %v", stats)
+	}
+}
+
+func TestEstimateSetFootprint(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	for _, key := range []string{"child:1", "child:2"} {
+		if err := s.Set(ctx, key, "some child value", 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	if err := s.SAdd(ctx, "parent", "child:1", "child:2", "child:missing"); err != nil {
+		t.Fatalf("SAdd: %v", err)
+	}
+
+	total, perKey, err := s.EstimateSetFootprint(ctx, "parent")
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("EstimateSetFootprint: %v", err)
+	}
+
+	if total <= 0 {
+		t.Fatalf("total = %d, want a positive footprint", total)
+	}
+
+	if len(perKey) != 3 || perKey["parent"] <= 0 || perKey["child:1"] <= 0 || perKey["child:2"] <= 0 {
+		t.Fatalf("perKey = %v, want the set and both existing children", perKey)
+	}
+}
+
+func TestEstimateSetFootprintHonorsDisallowedCommands(t *testing.T) {
+	for _, command := range []string{"SMEMBERS", "MEMORY USAGE", "MEMORY"} {
+		s := NewService(testLogger, &ConnectConfig{DisallowedCommands: []string{command}}, "test")
+
+		if _, _, err := s.EstimateSetFootprint(testContext(t), "set"); !errors.Is(err, ErrCommandDisabled) {
+			t.Fatalf("EstimateSetFootprint with %s disallowed: %v, want ErrCommandDisabled", command, err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package earedis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// retryablePrefixes — reply errors signalling a transient server state.
+var retryablePrefixes = []string{"LOADING ", "READONLY ", "TRYAGAIN ", "CLUSTERDOWN ", "MASTERDOWN ", "ERR max number of clients"}
+
+// IsRetryable — reports whether a command error is transient: network failures and
+// LOADING, READONLY, TRYAGAIN, CLUSTERDOWN and MASTERDOWN replies. redis.Nil and context errors are not.
+// A transient error does not make re-sending safe by itself, see ResilientPipeline.IsIdempotent.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, rdb.Nil) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	for _, prefix := range retryablePrefixes {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// idempotentCommands — commands that leave the same result when run twice: reads and absolute writes.
+var idempotentCommands = newCommandSet([]string{
+	"GET", "MGET", "GETRANGE", "STRLEN", "EXISTS", "TYPE", "TTL", "PTTL", "SCAN", "DBSIZE", "LCS",
+	"HGET", "HMGET", "HGETALL", "HKEYS", "HVALS", "HLEN", "HEXISTS", "HSCAN",
+	"SMEMBERS", "SISMEMBER", "SMISMEMBER", "SCARD", "SSCAN",
+	"LRANGE", "LLEN", "LINDEX", "ZRANGE", "ZSCORE", "ZCARD", "ZRANK", "ZSCAN", "XRANGE", "XLEN",
+	"GETBIT", "BITCOUNT", "PFCOUNT", "JSON.GET", "PING", "ECHO", "INFO", "OBJECT", "MEMORY",
+	"SET", "SETEX", "PSETEX", "MSET", "DEL", "UNLINK", "EXPIRE", "PEXPIRE", "EXPIREAT", "PEXPIREAT", "PERSIST",
+	"HSET", "HMSET", "HDEL", "SADD", "SREM", "ZREM", "SETBIT", "PFADD", "JSON.SET", "JSON.DEL",
+})
+
+// IsIdempotent — reports whether running cmd twice is as good as running it once: reads and absolute
+// writes like SET, DEL and HSET. Counters, list pushes and pops, scripts and everything unknown are not.
+func IsIdempotent(cmd rdb.Cmder) bool {
+	_, ok := idempotentCommands[strings.ToUpper(cmd.Name())]
+	return ok
+}
+
+// unsent — reports whether err proves the command never ran: the server refused it with a reply
+// (LOADING, READONLY, ...) or the connection could not be established. A timeout or dropped connection
+// may strike after the server already ran the command.
+func unsent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var redisErr rdb.Error
+	return errors.As(err, &redisErr)
+}
+
+// ResilientPipeline — a pipeline that, after Exec, re-issues only the commands that failed with
+// a retryable error, in follow-up pipelines with a linear backoff, instead of re-running the whole batch.
+// Commands that may have run despite the error (a timeout or dropped connection) are re-issued only if
+// IsIdempotent allows it, so an INCR or RPUSH is never applied twice.
+// It is not safe for concurrent use.
+type ResilientPipeline struct {
+	s *Service
+
+	// MaxRetries — follow-up pipelines issued at most.
+	MaxRetries int
+	// Backoff — delay before the first follow-up, growing linearly with each retry.
+	Backoff time.Duration
+	// IsRetryable — classifies command errors, IsRetryable by default.
+	IsRetryable func(err error) bool
+	// IsIdempotent — classifies commands safe to re-send after an ambiguous failure, IsIdempotent by default.
+	IsIdempotent func(cmd rdb.Cmder) bool
+
+	cmds []rdb.Cmder
+}
+
+// NewResilientPipeline — returns an empty ResilientPipeline bound to the service.
+func (s *Service) NewResilientPipeline(maxRetries int, backoff time.Duration) *ResilientPipeline {
+	return &ResilientPipeline{
+		s: s,
+
+		MaxRetries:   maxRetries,
+		Backoff:      backoff,
+		IsRetryable:  IsRetryable,
+		IsIdempotent: IsIdempotent,
+	}
+}
+
+// Do — queues a command given by its arguments, e.g. Do(ctx, "set", "key", "value").
+func (p *ResilientPipeline) Do(ctx *eactx.Context, args ...interface{}) *rdb.Cmd {
+	cmd := rdb.NewCmd(ctx.GetContext(), args...)
+	p.cmds = append(p.cmds, cmd)
+	return cmd
+}
+
+// Process — queues a prebuilt command, e.g. one from rdb.NewStringCmd.
+func (p *ResilientPipeline) Process(cmd rdb.Cmder) {
+	p.cmds = append(p.cmds, cmd)
+}
+
+// Len — returns the number of queued commands.
+func (p *ResilientPipeline) Len() int {
+	return len(p.cmds)
+}
+
+// Exec — sends the queued commands and retries the retryable failures, then empties the queue.
+// Returns the commands in queue order with their final results, and the joined errors of the
+// commands that still failed (redis.Nil replies are not errors). The commands are sent as queued,
+// so Exec returns ErrNotNamespaced while ConnectConfig.TenantExtractor is set.
+func (p *ResilientPipeline) Exec(ctx *eactx.Context) (results []rdb.Cmder, err error) {
+	defer p.s.reportReturned("ResilientPipeline.Exec", "", &err)
+
+	cmds := p.cmds
+	p.cmds = nil
+
+	if err := p.s.requireNamespace("ResilientPipeline"); err != nil {
+		return nil, err
+	}
+
+	for _, cmd := range cmds {
+		if err := p.s.checkCommand(cmderCommand(cmd)); err != nil {
+			return nil, err
+		}
+	}
+
+	pending := cmds
+	for attempt := 0; len(pending) > 0; attempt++ {
+		p.exec(ctx.GetContext(), pending)
+
+		retry := make([]rdb.Cmder, 0)
+		for _, cmd := range pending {
+			if p.retryable(cmd) {
+				retry = append(retry, cmd)
+			}
+		}
+
+		if len(retry) == 0 || attempt >= p.MaxRetries {
+			break
+		}
+
+		p.s.l.WarnT(p.s.traceName, "Retrying failed pipeline commands", len(retry), "of", len(cmds))
+
+		select {
+		case <-ctx.Done():
+			return cmds, ctx.Err()
+		case <-time.After(p.Backoff * time.Duration(attempt+1)):
+		}
+
+		pending = retry
+	}
+
+	var errs []error
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil && !errors.Is(err, rdb.Nil) {
+			errs = append(errs, fmt.Errorf("earedis: pipeline command %d (%s): %w", i, cmd.Name(), err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		p.s.l.ErrorT(p.s.traceName, "Failed to execute pipeline", err)
+		return cmds, err
+	}
+
+	return cmds, nil
+}
+
+// retryable — reports whether cmd failed transiently and can be re-sent without applying it twice.
+func (p *ResilientPipeline) retryable(cmd rdb.Cmder) bool {
+	err := cmd.Err()
+	if !p.IsRetryable(err) {
+		return false
+	}
+
+	return unsent(err) || p.IsIdempotent(cmd)
+}
+
+// exec — sends one batch; per-command results are left on the commands themselves.
+func (p *ResilientPipeline) exec(ctx context.Context, cmds []rdb.Cmder) {
+	_ = p.s.pipelined(ctx, p.s.client, len(cmds), func(pipe rdb.Pipeliner, i int) {
+		cmds[i].SetErr(nil)
+		_ = pipe.Process(ctx, cmds[i])
+	})
+}
+
+// pipelined — queues count commands, the i-th by queue(pipe, i), and executes them on client in sequential
+// pipelines of at most ConnectConfig.MaxPipelineSize commands. Every pipeline is sent even after a failed one,
+// so each command carries its own result; the first error is returned, like rdb.Pipelined does for a single pipeline.
+func (s *Service) pipelined(ctx context.Context, client rdb.Cmdable, count int, queue func(pipe rdb.Pipeliner, i int)) error {
+	size := s.c.MaxPipelineSize
+	if size <= 0 {
+		size = count
+	}
+
+	var firstErr error
+	for start := 0; start < count; start += size {
+		end := min(start+size, count)
+
+		_, err := client.Pipelined(ctx, func(pipe rdb.Pipeliner) error {
+			for i := start; i < end; i++ {
+				queue(pipe, i)
+			}
+
+			return nil
+		})
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
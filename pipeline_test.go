@@ -0,0 +1,250 @@
+package earedis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	rdb "github.com/redis/go-redis/v9"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// replyError — an injected server reply error.
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+
+func (replyError) RedisError() {}
+
+// faultHook — records the pipelines sent and fails chosen commands the first time they are sent.
+// The next drop pipelines fail as a whole without being sent, as if the connection could not be made.
+type faultHook struct {
+	mu     sync.Mutex
+	faults map[string]error
+	drop   int
+	sent   [][]string
+}
+
+func (h *faultHook) DialHook(next rdb.DialHook) rdb.DialHook {
+	return next
+}
+
+func (h *faultHook) ProcessHook(next rdb.ProcessHook) rdb.ProcessHook {
+	return next
+}
+
+func (h *faultHook) ProcessPipelineHook(next rdb.ProcessPipelineHook) rdb.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []rdb.Cmder) error {
+		h.mu.Lock()
+		if h.drop > 0 {
+			h.drop--
+			h.mu.Unlock()
+
+			err := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+
+			return err
+		}
+		h.mu.Unlock()
+
+		err := next(ctx, cmds)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		batch := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			batch[i] = strings.TrimSpace(cmd.Name() + " " + commandKey(cmd))
+			if fault, ok := h.faults[batch[i]]; ok {
+				delete(h.faults, batch[i])
+				cmd.SetErr(fault)
+			}
+		}
+
+		h.sent = append(h.sent, batch)
+		return err
+	}
+}
+
+func TestResilientPipelineRetriesFailedSubset(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	hook := &faultHook{faults: map[string]error{
+		"set a":  replyError("LOADING Redis is loading the dataset in memory"),
+		"incr b": timeout,
+		"get c":  io.EOF,
+	}}
+	s.client.AddHook(hook)
+
+	pipe := s.NewResilientPipeline(3, time.Millisecond)
+	setA := pipe.Do(ctx, "set", "a", "1")
+	incrB := pipe.Do(ctx, "incr", "b")
+	getC := pipe.Do(ctx, "get", "c")
+	setD := pipe.Do(ctx, "set", "d", "4")
+
+	_, err := pipe.Exec(ctx)
+	if err == nil {
+		t.Fatal("Exec succeeded although INCR b failed")
+	}
+
+	want := [][]string{{"set a", "incr b", "get c", "set d"}, {"set a", "get c"}}
+	if !reflect.DeepEqual(hook.sent, want) {
+		t.Fatalf("sent pipelines %v, want %v", hook.sent, want)
+	}
+
+	if setA.Err() != nil || setD.Err() != nil {
+		t.Fatalf("SET errors: %v, %v", setA.Err(), setD.Err())
+	}
+
+	if !errors.Is(getC.Err(), rdb.Nil) {
+		t.Fatalf("GET c error = %v, want redis.Nil after the retry", getC.Err())
+	}
+
+	// The INCR may have run before the timeout; it must not be re-sent.
+	if !errors.Is(incrB.Err(), os.ErrDeadlineExceeded) {
+		t.Fatalf("INCR b error = %v, want the injected timeout", incrB.Err())
+	}
+}
+
+func TestResilientPipelineChunksInOrder(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.MaxPipelineSize = 3
+	})
+	ctx := testContext(t)
+
+	hook := &faultHook{}
+	s.client.AddHook(hook)
+
+	pipe := s.NewResilientPipeline(0, 0)
+	for i := 0; i < 5; i++ {
+		pipe.Do(ctx, "set", fmt.Sprint("k", i), fmt.Sprint("v", i))
+	}
+	for i := 0; i < 5; i++ {
+		pipe.Do(ctx, "get", fmt.Sprint("k", i))
+	}
+
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+
+	want := [][]string{{"set k0", "set k1", "set k2"}, {"set k3", "set k4", "get k0"}, {"get k1", "get k2", "get k3"}, {"get k4"}}
+	if !reflect.DeepEqual(hook.sent, want) {
+		t.Fatalf("sent pipelines %v, want %v", hook.sent, want)
+	}
+
+	if len(cmds) != 10 {
+		t.Fatalf("Exec returned %d commands, want 10", len(cmds))
+	}
+
+	for i, cmd := range cmds[5:] {
+		if got := cmd.(*rdb.Cmd).Val(); got != fmt.Sprint("v", i) {
+			t.Fatalf("result %d = %v, want v%d", 5+i, got, i)
+		}
+	}
+}
+
+func TestResilientPipelineSendsChunksAfterFailure(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.MaxPipelineSize = 2
+	})
+	ctx := testContext(t)
+
+	for i := 0; i < 4; i++ {
+		if err := s.Set(ctx, fmt.Sprint("k", i), fmt.Sprint("v", i), 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	hook := &faultHook{drop: 1}
+	s.client.AddHook(hook)
+
+	pipe := s.NewResilientPipeline(0, 0)
+	for i := 0; i < 4; i++ {
+		pipe.Do(ctx, "get", fmt.Sprint("k", i))
+	}
+
+	cmds, err := pipe.Exec(ctx)
+	if err == nil {
+		t.Fatal("Exec succeeded although the first chunk was never sent")
+	}
+
+	// The chunk after the failed one is still sent and carries real results.
+	if want := [][]string{{"get k2", "get k3"}}; !reflect.DeepEqual(hook.sent, want) {
+		t.Fatalf("sent pipelines %v, want %v", hook.sent, want)
+	}
+
+	for i, cmd := range cmds {
+		if i < 2 {
+			if !unsent(cmd.Err()) {
+				t.Fatalf("command %d error = %v, want the dial failure", i, cmd.Err())
+			}
+			continue
+		}
+
+		if got := cmd.(*rdb.Cmd).Val(); cmd.Err() != nil || got != fmt.Sprint("v", i) {
+			t.Fatalf("command %d = %v, %v; want v%d", i, got, cmd.Err(), i)
+		}
+	}
+
+	// With a retry the unsent chunk is re-sent alone.
+	hook.drop, hook.sent = 1, nil
+	pipe = s.NewResilientPipeline(1, time.Millisecond)
+	for i := 0; i < 4; i++ {
+		pipe.Do(ctx, "get", fmt.Sprint("k", i))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatalf("Exec with a retry: %v", err)
+	}
+
+	if want := [][]string{{"get k2", "get k3"}, {"get k0", "get k1"}}; !reflect.DeepEqual(hook.sent, want) {
+		t.Fatalf("sent pipelines %v, want %v", hook.sent, want)
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		cmd  rdb.Cmder
+		want bool
+	}{
+		{rdb.NewStringCmd(ctx, "get", "k"), true},
+		{rdb.NewStatusCmd(ctx, "set", "k", "v"), true},
+		{rdb.NewIntCmd(ctx, "del", "k"), true},
+		{rdb.NewIntCmd(ctx, "incr", "k"), false},
+		{rdb.NewIntCmd(ctx, "rpush", "k", "v"), false},
+		{rdb.NewCmd(ctx, "evalsha", "sha", 1, "k"), false},
+	} {
+		if got := IsIdempotent(tc.cmd); got != tc.want {
+			t.Errorf("IsIdempotent(%v) = %v, want %v", tc.cmd.Args(), got, tc.want)
+		}
+	}
+}
+
+func TestUnsent(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{replyError("LOADING Redis is loading"), true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{&net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, false},
+		{io.EOF, false},
+	} {
+		if got := unsent(tc.err); got != tc.want {
+			t.Errorf("unsent(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package earedis
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"time"
+)
+
+// claimSlotScript — leases the first slot in [0, ARGV[1]) of the pool hash whose lease is missing or expired.
+// Each hash field maps a slot to "<expiry ms>:<token>", with the expiry taken from the server clock.
+// Returns the claimed slot, or -1 when every slot is leased.
+var claimSlotScript = rdb.NewScript(`
+local now = redis.call('TIME')
+local nowMs = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local size, ttl = tonumber(ARGV[1]), tonumber(ARGV[2])
+
+for slot = 0, size - 1 do
+	local lease = redis.call('HGET', KEYS[1], slot)
+	local expiry = lease and tonumber(string.match(lease, '^(%d+):'))
+	if not lease or expiry == nil or expiry <= nowMs then
+		redis.call('HSET', KEYS[1], slot, string.format('%d', nowMs + ttl) .. ':' .. ARGV[3])
+		if redis.call('PTTL', KEYS[1]) < ttl then
+			redis.call('PEXPIRE', KEYS[1], ttl)
+		end
+		return slot
+	end
+end
+
+return -1
+`)
+
+// releaseSlotScript — removes the lease of slot ARGV[1] if it is held with token ARGV[2].
+var releaseSlotScript = rdb.NewScript(`
+local lease = redis.call('HGET', KEYS[1], ARGV[1])
+if not lease or string.match(lease, '^%d+:(.*)$') ~= ARGV[2] then
+	return 0
+end
+
+redis.call('HDEL', KEYS[1], ARGV[1])
+return 1
+`)
+
+// ClaimSlot — atomically leases the lowest free slot in [0, size) of the pool stored at poolKey for ttl.
+// The returned token proves ownership for ReleaseSlot; a lease that is not released expires after ttl
+// and its slot becomes claimable again. Returns ErrPoolExhausted when every slot is leased.
+// size must be positive and ttl at least a millisecond.
+func (s *Service) ClaimSlot(ctx *eactx.Context, poolKey string, size int, ttl time.Duration) (slot int, token string, err error) {
+	defer s.reportReturned("ClaimSlot", poolKey, &err)
+
+	if err := s.checkCommand("EVAL"); err != nil {
+		return 0, "", err
+	}
+
+	// A zero TTL would PEXPIRE the pool hash, and with it the leases of every other holder, right away.
+	if size <= 0 || ttl < time.Millisecond {
+		return 0, "", fmt.Errorf("earedis: invalid pool size %d or lease ttl %v", size, ttl)
+	}
+
+	poolKey, err = s.tenantKey(ctx, poolKey)
+	if err != nil {
+		return 0, "", err
+	}
+
+	token, err = newToken()
+	if err != nil {
+		return 0, "", err
+	}
+
+	result, err := claimSlotScript.Run(ctx.GetContext(), s.client, []string{poolKey}, size, ttl.Milliseconds(), token).Int()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to claim slot from pool", poolKey, err)
+		return 0, "", err
+	}
+
+	if result < 0 {
+		return 0, "", ErrPoolExhausted
+	}
+
+	return result, token, nil
+}
+
+// ReleaseSlot — frees a slot claimed by ClaimSlot. Returns ErrLeaseNotHeld when the lease has expired
+// or was reclaimed by someone else, in which case the slot is left untouched.
+func (s *Service) ReleaseSlot(ctx *eactx.Context, poolKey string, slot int, token string) (err error) {
+	defer s.reportReturned("ReleaseSlot", poolKey, &err)
+
+	if err := s.checkCommand("EVAL"); err != nil {
+		return err
+	}
+
+	poolKey, err = s.tenantKey(ctx, poolKey)
+	if err != nil {
+		return err
+	}
+
+	released, err := releaseSlotScript.Run(ctx.GetContext(), s.client, []string{poolKey}, slot, token).Int()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to release slot from pool", poolKey, slot, err)
+		return err
+	}
+
+	if released == 0 {
+		return ErrLeaseNotHeld
+	}
+
+	return nil
+}
+
+// newToken — returns a random hex token identifying a lease holder.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
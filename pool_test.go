@@ -0,0 +1,76 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClaimSlotExhaustion(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	tokens := make(map[int]string)
+	for i := 0; i < 2; i++ {
+		slot, token, err := s.ClaimSlot(ctx, "pool", 2, time.Minute)
+		if err != nil {
+			t.Fatalf("ClaimSlot: %v", err)
+		}
+
+		tokens[slot] = token
+	}
+
+	if len(tokens) != 2 {
+		t.Fatalf("claimed slots %v, want 0 and 1", tokens)
+	}
+
+	if _, _, err := s.ClaimSlot(ctx, "pool", 2, time.Minute); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("ClaimSlot on a full pool: %v, want ErrPoolExhausted", err)
+	}
+
+	if err := s.ReleaseSlot(ctx, "pool", 1, "not-the-token"); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("ReleaseSlot with a foreign token: %v, want ErrLeaseNotHeld", err)
+	}
+
+	if err := s.ReleaseSlot(ctx, "pool", 1, tokens[1]); err != nil {
+		t.Fatalf("ReleaseSlot: %v", err)
+	}
+
+	if slot, _, err := s.ClaimSlot(ctx, "pool", 2, time.Minute); err != nil || slot != 1 {
+		t.Fatalf("ClaimSlot after release = %d, %v; want 1", slot, err)
+	}
+}
+
+func TestClaimSlotReclaimsExpiredLease(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	_, stale, err := s.ClaimSlot(ctx, "pool", 1, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ClaimSlot: %v", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	slot, _, err := s.ClaimSlot(ctx, "pool", 1, time.Minute)
+	if err != nil || slot != 0 {
+		t.Fatalf("ClaimSlot after expiry = %d, %v; want 0", slot, err)
+	}
+
+	if err := s.ReleaseSlot(ctx, "pool", 0, stale); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("ReleaseSlot of the expired lease: %v, want ErrLeaseNotHeld", err)
+	}
+}
+
+func TestClaimSlotRejectsInvalidArguments(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+
+	for _, args := range []struct {
+		size int
+		ttl  time.Duration
+	}{{0, time.Minute}, {-1, time.Minute}, {2, 0}, {2, -time.Second}} {
+		if _, _, err := s.ClaimSlot(testContext(t), "pool", args.size, args.ttl); err == nil {
+			t.Fatalf("ClaimSlot(%d, %v) succeeded", args.size, args.ttl)
+		}
+	}
+}
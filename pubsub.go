@@ -0,0 +1,307 @@
+package earedis
+
+import (
+	"context"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+)
+
+// SPublish — publishes a message to a shard channel (SPUBLISH).
+// Sharded pub/sub requires Redis 7.0+ and only scales out in cluster mode,
+// where a message is delivered solely within the shard owning the channel slot.
+// Shard channels are not namespaced with ConnectConfig.ChannelPrefix or the tenant, so SPublish returns
+// ErrNotNamespaced while ConnectConfig.TenantExtractor is set.
+func (s *Service) SPublish(ctx *eactx.Context, channel string, message interface{}) (err error) {
+	defer s.reportReturned("SPublish", "", &err)
+
+	if err := s.checkCommand("SPUBLISH"); err != nil {
+		return err
+	}
+
+	if err := s.requireNamespace("SPUBLISH"); err != nil {
+		return err
+	}
+
+	if err := s.requireVersion("SPUBLISH", 7, 0); err != nil {
+		return err
+	}
+
+	if err := s.checkValueSize(channel, message); err != nil {
+		return err
+	}
+
+	if err := s.client.SPublish(ctx.GetContext(), channel, message).Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to publish to shard channel", channel, err)
+		return err
+	}
+
+	return nil
+}
+
+// SSubscribe — subscribes to shard channels (SSUBSCRIBE), see SPublish for the requirements.
+// The caller owns the returned PubSub and must close it.
+func (s *Service) SSubscribe(ctx *eactx.Context, channels ...string) (sub *rdb.PubSub, err error) {
+	defer s.reportReturned("SSubscribe", "", &err)
+
+	if err := s.checkCommand("SSUBSCRIBE"); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireNamespace("SSUBSCRIBE"); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireVersion("SSUBSCRIBE", 7, 0); err != nil {
+		return nil, err
+	}
+
+	pubsub := s.client.SSubscribe(ctx.GetContext(), channels...)
+
+	// Wait for the subscription confirmation so that errors surface here.
+	if _, err := pubsub.Receive(ctx.GetContext()); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to subscribe to shard channels", channels, err)
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	return pubsub, nil
+}
+
+// PubSub — a subscription made through Subscribe or PSubscribe.
+// Channel and pattern names are namespaced with ConnectConfig.ChannelPrefix and the tenant on the wire
+// and delivered without them, so callers only ever see the names they subscribed with.
+type PubSub struct {
+	pubsub *rdb.PubSub
+
+	s      *Service
+	tenant string
+
+	once sync.Once
+	ch   chan *rdb.Message
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe — subscribes to additional channels.
+func (p *PubSub) Subscribe(ctx context.Context, channels ...string) error {
+	return p.pubsub.Subscribe(ctx, p.s.channels(p.tenant, channels)...)
+}
+
+// PSubscribe — subscribes to additional channel patterns.
+func (p *PubSub) PSubscribe(ctx context.Context, patterns ...string) error {
+	return p.pubsub.PSubscribe(ctx, p.s.channels(p.tenant, patterns)...)
+}
+
+// Unsubscribe — unsubscribes from the channels, or from all channels when none are given.
+func (p *PubSub) Unsubscribe(ctx context.Context, channels ...string) error {
+	return p.pubsub.Unsubscribe(ctx, p.s.channels(p.tenant, channels)...)
+}
+
+// PUnsubscribe — unsubscribes from the patterns, or from all patterns when none are given.
+func (p *PubSub) PUnsubscribe(ctx context.Context, patterns ...string) error {
+	return p.pubsub.PUnsubscribe(ctx, p.s.channels(p.tenant, patterns)...)
+}
+
+// Ping — pings the server over the subscription connection.
+func (p *PubSub) Ping(ctx context.Context, payload ...string) error {
+	return p.pubsub.Ping(ctx, payload...)
+}
+
+// Receive — waits for the next message, subscription confirmation or pong, see rdb.PubSub.Receive.
+func (p *PubSub) Receive(ctx context.Context) (interface{}, error) {
+	msg, err := p.pubsub.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.strip(msg), nil
+}
+
+// ReceiveMessage — waits for the next message.
+func (p *PubSub) ReceiveMessage(ctx context.Context) (*rdb.Message, error) {
+	msg, err := p.pubsub.ReceiveMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.s.stripChannel(p.tenant, msg), nil
+}
+
+// Channel — returns the channel of delivered messages, closed together with the subscription.
+// A consumer that stops reading must Close the subscription, which also stops the delivery.
+func (p *PubSub) Channel(opts ...rdb.ChannelOption) <-chan *rdb.Message {
+	p.once.Do(func() {
+		in := p.pubsub.Channel(opts...)
+		p.ch = make(chan *rdb.Message, cap(in))
+
+		go func() {
+			defer close(p.ch)
+			for msg := range in {
+				select {
+				case p.ch <- p.s.stripChannel(p.tenant, msg):
+				case <-p.done:
+					return
+				}
+			}
+		}()
+	})
+
+	return p.ch
+}
+
+// ChannelWithSubscriptions — like Channel, but also delivers the subscription confirmations
+// (*rdb.Subscription) alongside the messages (*rdb.Message).
+func (p *PubSub) ChannelWithSubscriptions(opts ...rdb.ChannelOption) <-chan interface{} {
+	in := p.pubsub.ChannelWithSubscriptions(opts...)
+	out := make(chan interface{}, cap(in))
+
+	go func() {
+		defer close(out)
+		for msg := range in {
+			select {
+			case out <- p.strip(msg):
+			case <-p.done:
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Close — ends the subscription and stops the delivery to Channel and ChannelWithSubscriptions.
+func (p *PubSub) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return p.pubsub.Close()
+}
+
+// strip — removes the namespace from the names carried by a message or subscription confirmation.
+func (p *PubSub) strip(msg interface{}) interface{} {
+	switch msg := msg.(type) {
+	case *rdb.Message:
+		return p.s.stripChannel(p.tenant, msg)
+	case *rdb.Subscription:
+		msg.Channel = p.s.stripName(p.tenant, msg.Channel)
+		return msg
+	default:
+		return msg
+	}
+}
+
+// channel — namespaces a channel name with ConnectConfig.ChannelPrefix and the tenant prefix,
+// unless it is listed in ConnectConfig.UnprefixedChannels.
+func (s *Service) channel(tenant, name string) string {
+	if _, ok := s.unprefixed[name]; ok {
+		return name
+	}
+
+	return s.c.ChannelPrefix + tenant + name
+}
+
+func (s *Service) channels(tenant string, names []string) []string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = s.channel(tenant, name)
+	}
+
+	return result
+}
+
+// stripChannel — removes the namespace from the channel and pattern of a delivered message.
+func (s *Service) stripChannel(tenant string, msg *rdb.Message) *rdb.Message {
+	msg.Channel = s.stripName(tenant, msg.Channel)
+	if msg.Pattern != "" {
+		msg.Pattern = s.stripName(tenant, msg.Pattern)
+	}
+
+	return msg
+}
+
+// stripName — removes the namespace from a channel or pattern name, the reverse of channel.
+func (s *Service) stripName(tenant, name string) string {
+	if _, ok := s.unprefixed[name]; ok {
+		return name
+	}
+
+	return strings.TrimPrefix(name, s.c.ChannelPrefix+tenant)
+}
+
+// Publish — publishes a message to the channel, namespaced with ConnectConfig.ChannelPrefix and the tenant.
+func (s *Service) Publish(ctx *eactx.Context, channel string, message interface{}) (err error) {
+	defer s.reportReturned("Publish", "", &err)
+
+	if err := s.checkCommand("PUBLISH"); err != nil {
+		return err
+	}
+
+	if err := s.checkValueSize(channel, message); err != nil {
+		return err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Publish(ctx.GetContext(), s.channel(tenant, channel), message).Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to publish to channel", channel, err)
+		return err
+	}
+
+	return nil
+}
+
+// Subscribe — subscribes to channels, namespaced with ConnectConfig.ChannelPrefix and the tenant.
+// The caller owns the returned PubSub and must close it.
+func (s *Service) Subscribe(ctx *eactx.Context, channels ...string) (pubsub *PubSub, err error) {
+	defer s.reportReturned("Subscribe", "", &err)
+
+	return s.subscribe(ctx, channels...)
+}
+
+// subscribe — Subscribe without error reporting, for methods building on it.
+func (s *Service) subscribe(ctx *eactx.Context, channels ...string) (*PubSub, error) {
+	if err := s.checkCommand("SUBSCRIBE"); err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pubsub := s.client.Subscribe(ctx.GetContext(), s.channels(tenant, channels)...)
+	return s.subscribed(ctx, pubsub, tenant, channels)
+}
+
+// PSubscribe — subscribes to channel patterns, namespaced with ConnectConfig.ChannelPrefix and the tenant.
+// The caller owns the returned PubSub and must close it.
+func (s *Service) PSubscribe(ctx *eactx.Context, patterns ...string) (sub *PubSub, err error) {
+	defer s.reportReturned("PSubscribe", "", &err)
+
+	if err := s.checkCommand("PSUBSCRIBE"); err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pubsub := s.client.PSubscribe(ctx.GetContext(), s.channels(tenant, patterns)...)
+	return s.subscribed(ctx, pubsub, tenant, patterns)
+}
+
+// subscribed — waits for the subscription confirmation so that errors surface to the caller.
+func (s *Service) subscribed(ctx *eactx.Context, pubsub *rdb.PubSub, tenant string, channels []string) (*PubSub, error) {
+	if _, err := pubsub.Receive(ctx.GetContext()); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to subscribe to channels", channels, err)
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	return &PubSub{pubsub: pubsub, s: s, tenant: tenant, done: make(chan struct{})}, nil
+}
@@ -0,0 +1,135 @@
+package earedis
+
+import (
+	rdb "github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+func testShardedPubSub(t *testing.T, s *Service) {
+	ctx := testContext(t)
+
+	pubsub, err := s.SSubscribe(ctx, "orders")
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("SSubscribe: %v", err)
+	}
+	defer pubsub.Close()
+
+	if err := s.SPublish(ctx, "orders", "created"); err != nil {
+		t.Fatalf("SPublish: %v", err)
+	}
+
+	select {
+	case msg := <-pubsub.Channel():
+		if msg.Channel != "orders" || msg.Payload != "created" {
+			t.Fatalf("received %s %q, want orders \"created\"", msg.Channel, msg.Payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no shard message received")
+	}
+}
+
+func TestShardedPubSub(t *testing.T) {
+	testShardedPubSub(t, newTestService(t))
+}
+
+func TestShardedPubSubCluster(t *testing.T) {
+	testShardedPubSub(t, newTestClusterService(t))
+}
+
+func TestPrefixedServicesIsolated(t *testing.T) {
+	a := newTestService(t, func(c *ConnectConfig) { c.ChannelPrefix = "a:" })
+	b := newTestService(t, func(c *ConnectConfig) { c.ChannelPrefix = "b:" })
+	ctx := testContext(t)
+
+	subA, err := a.Subscribe(ctx, "events")
+	if err != nil {
+		t.Fatalf("Subscribe a: %v", err)
+	}
+	defer subA.Close()
+
+	subB, err := b.Subscribe(ctx, "events")
+	if err != nil {
+		t.Fatalf("Subscribe b: %v", err)
+	}
+	defer subB.Close()
+
+	if err := a.Publish(ctx, "events", "for a"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case msg := <-subA.Channel():
+		if msg.Channel != "events" || msg.Payload != "for a" {
+			t.Fatalf("a received %s %q, want events \"for a\"", msg.Channel, msg.Payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a received nothing")
+	}
+
+	select {
+	case msg := <-subB.Channel():
+		t.Fatalf("b received %s %q published for a", msg.Channel, msg.Payload)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestPubSubReceiveStripsPrefix(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) { c.ChannelPrefix = "svc:" })
+	ctx := testContext(t)
+
+	sub, err := s.Subscribe(ctx, "events")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	if err := sub.Subscribe(ctx.GetContext(), "more"); err != nil {
+		t.Fatalf("Subscribe more: %v", err)
+	}
+
+	msg, err := sub.Receive(ctx.GetContext())
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	if confirmation, ok := msg.(*rdb.Subscription); !ok || confirmation.Channel != "more" {
+		t.Fatalf("Receive = %#v, want the confirmation of more", msg)
+	}
+}
+
+func TestPubSubCloseStopsChannel(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	sub, err := s.Subscribe(ctx, "events")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	messages := sub.Channel(rdb.WithChannelSize(1))
+	for i := 0; i < 5; i++ {
+		if err := s.Publish(ctx, "events", i); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	// Let the delivery fill the buffer and block, then close without reading.
+	time.Sleep(100 * time.Millisecond)
+	if err := sub.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Channel not closed after Close")
+		}
+	}
+}
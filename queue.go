@@ -0,0 +1,94 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+// enqueueUniqueScript — RPUSHes ARGV[1] to KEYS[1] only if it is not yet a member of the dedup set KEYS[2].
+var enqueueUniqueScript = rdb.NewScript(`
+if redis.call('SADD', KEYS[2], ARGV[1]) == 0 then
+	return 0
+end
+
+redis.call('RPUSH', KEYS[1], ARGV[1])
+return 1
+`)
+
+// EnqueueUnique — atomically appends job to the list at queueKey unless it is already recorded in the set at
+// dedupSetKey, returning whether it was enqueued. The job stays recorded until CompleteUnique, so a job is
+// rejected while it is queued or being processed. In cluster mode both keys must share a hash tag.
+func (s *Service) EnqueueUnique(ctx *eactx.Context, queueKey, dedupSetKey string, job string) (enqueued bool, err error) {
+	defer s.reportReturned("EnqueueUnique", queueKey, &err)
+
+	if err := s.checkCommand("EVAL"); err != nil {
+		return false, err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.checkValueSize(queueKey, job); err != nil {
+		return false, err
+	}
+
+	result, err := enqueueUniqueScript.Run(ctx.GetContext(), s.client, []string{tenant + queueKey, tenant + dedupSetKey}, job).Int()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to enqueue job to queue", queueKey, err)
+		return false, err
+	}
+
+	return result == 1, nil
+}
+
+// DequeueUnique — pops the oldest job from the list at queueKey, returning ErrNotFound when the queue is empty.
+// Call CompleteUnique once the job is processed to allow enqueueing it again.
+func (s *Service) DequeueUnique(ctx *eactx.Context, queueKey string) (job string, err error) {
+	defer s.reportReturned("DequeueUnique", queueKey, &err)
+
+	if err := s.checkCommand("LPOP"); err != nil {
+		return "", err
+	}
+
+	queueKey, err = s.tenantKey(ctx, queueKey)
+	if err != nil {
+		return "", err
+	}
+
+	job, err = s.client.LPop(ctx.GetContext(), queueKey).Result()
+	if errors.Is(err, rdb.Nil) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, queueKey)
+	}
+
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to dequeue job from queue", queueKey, err)
+		return "", err
+	}
+
+	return job, nil
+}
+
+// CompleteUnique — removes a processed job from the set at dedupSetKey, so that it can be enqueued again.
+func (s *Service) CompleteUnique(ctx *eactx.Context, dedupSetKey string, job string) (err error) {
+	defer s.reportReturned("CompleteUnique", dedupSetKey, &err)
+
+	if err := s.checkCommand("SREM"); err != nil {
+		return err
+	}
+
+	dedupSetKey, err = s.tenantKey(ctx, dedupSetKey)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.SRem(ctx.GetContext(), dedupSetKey, job).Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to complete job at key", dedupSetKey, err)
+		return err
+	}
+
+	return nil
+}
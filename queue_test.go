@@ -0,0 +1,52 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnqueueUniqueRejectsDuplicates(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	enqueue := func(job string, want bool) {
+		t.Helper()
+
+		enqueued, err := s.EnqueueUnique(ctx, "{jobs}:queue", "{jobs}:dedup", job)
+		if err != nil || enqueued != want {
+			t.Fatalf("EnqueueUnique(%s) = %v, %v; want %v", job, enqueued, err, want)
+		}
+	}
+
+	enqueue("a", true)
+	enqueue("b", true)
+	enqueue("a", false)
+
+	if n := s.client.LLen(ctx.GetContext(), "{jobs}:queue").Val(); n != 2 {
+		t.Fatalf("queue length = %d, want 2", n)
+	}
+
+	job, err := s.DequeueUnique(ctx, "{jobs}:queue")
+	if err != nil || job != "a" {
+		t.Fatalf("DequeueUnique = %q, %v; want \"a\"", job, err)
+	}
+
+	// Still rejected while being processed.
+	enqueue("a", false)
+
+	if err := s.CompleteUnique(ctx, "{jobs}:dedup", "a"); err != nil {
+		t.Fatalf("CompleteUnique: %v", err)
+	}
+
+	enqueue("a", true)
+
+	for _, want := range []string{"b", "a"} {
+		if job, err := s.DequeueUnique(ctx, "{jobs}:queue"); err != nil || job != want {
+			t.Fatalf("DequeueUnique = %q, %v; want %q", job, err, want)
+		}
+	}
+
+	if _, err := s.DequeueUnique(ctx, "{jobs}:queue"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("DequeueUnique on empty queue: %v, want ErrNotFound", err)
+	}
+}
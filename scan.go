@@ -0,0 +1,134 @@
+package earedis
+
+import (
+	"context"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
+)
+
+// forEachNode — calls fn for every node holding a share of the keyspace:
+// concurrently for each master in cluster mode, once for the single server otherwise.
+func (s *Service) forEachNode(ctx context.Context, fn func(ctx context.Context, client rdb.Cmdable) error) error {
+	if cluster, ok := s.client.(*rdb.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *rdb.Client) error {
+			return fn(ctx, client)
+		})
+	}
+
+	return fn(ctx, s.client)
+}
+
+// CountKeys — counts the keys matching pattern by SCAN-iterating the keyspace in batches of count.
+// Unlike KEYS it does not block the server, and the matched keys are never held in memory.
+// The count is approximate: SCAN may return a key more than once (e.g. while the keyspace is rehashed),
+// and such a key is counted every time. Keys added or removed during the scan may or may not be counted.
+func (s *Service) CountKeys(ctx *eactx.Context, pattern string, count int64) (n int64, err error) {
+	defer s.reportReturned("CountKeys", "", &err)
+
+	if err := s.checkCommand("SCAN"); err != nil {
+		return 0, err
+	}
+
+	pattern, err = s.tenantKey(ctx, pattern)
+	if err != nil {
+		return 0, err
+	}
+
+	var total atomic.Int64
+
+	err = s.forEachNode(ctx.GetContext(), func(ctx context.Context, client rdb.Cmdable) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, count).Result()
+			if err != nil {
+				return err
+			}
+
+			total.Add(int64(len(keys)))
+			cursor = next
+
+			if cursor == 0 {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to scan keys by pattern", pattern, err)
+		return 0, err
+	}
+
+	s.l.DebugT(s.traceName, "Counted keys by pattern", pattern, total.Load())
+	return total.Load(), nil
+}
+
+// ScanWithProgress — SCAN-iterates the keys matching pattern in batches of count, passing every batch
+// (possibly empty) together with the running total of scanned keys to onBatch. Returning false from
+// onBatch stops the scan and ScanWithProgress returns nil; cancelling ctx stops it with the context error.
+// onBatch is never called concurrently, also in cluster mode where nodes are scanned in parallel.
+func (s *Service) ScanWithProgress(ctx *eactx.Context, pattern string, count int64, onBatch func(keys []string, scanned int64) bool) (err error) {
+	defer s.reportReturned("ScanWithProgress", "", &err)
+
+	if err := s.checkCommand("SCAN"); err != nil {
+		return err
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	pattern = tenant + pattern
+
+	scanCtx, cancel := context.WithCancel(ctx.GetContext())
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		scanned int64
+		aborted bool
+	)
+
+	err = s.forEachNode(scanCtx, func(ctx context.Context, client rdb.Cmdable) error {
+		var cursor uint64
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			keys, next, err := client.Scan(ctx, cursor, pattern, count).Result()
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			if !aborted {
+				scanned += int64(len(keys))
+				if !onBatch(stripTenant(tenant, keys), scanned) {
+					aborted = true
+					cancel()
+				}
+			}
+			stop := aborted
+			mu.Unlock()
+
+			cursor = next
+			if stop || cursor == 0 {
+				return nil
+			}
+		}
+	})
+
+	if aborted {
+		s.l.InfoT(s.traceName, "Aborted scan by pattern", pattern, scanned)
+		return nil
+	}
+
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to scan keys by pattern", pattern, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package earedis
+
+import (
+	"fmt"
+	rdb "github.com/redis/go-redis/v9"
+	"testing"
+)
+
+// seedKeys — sets n keys named prefix0..prefix<n-1> to their index.
+func seedKeys(t *testing.T, s *Service, prefix string, n int) {
+	t.Helper()
+
+	ctx := testContext(t)
+	_, err := s.client.Pipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		for i := 0; i < n; i++ {
+			pipe.Set(ctx.GetContext(), fmt.Sprintf("%s%d", prefix, i), i, 0)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed %s: %v", prefix, err)
+	}
+}
+
+func TestCountKeysPrefixSubset(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	seedKeys(t, s, "user:", 300)
+	seedKeys(t, s, "order:", 700)
+
+	got, err := s.CountKeys(ctx, "user:*", 50)
+	if err != nil {
+		t.Fatalf("CountKeys: %v", err)
+	}
+
+	if got != 300 {
+		t.Fatalf("CountKeys(user:*) = %d, want 300", got)
+	}
+}
+
+func TestScanWithProgressAbortsMidway(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	seedKeys(t, s, "item:", 500)
+
+	calls := 0
+	var seen int64
+	err := s.ScanWithProgress(ctx, "item:*", 20, func(keys []string, scanned int64) bool {
+		calls++
+		seen = scanned
+		return scanned < 100
+	})
+	if err != nil {
+		t.Fatalf("ScanWithProgress: %v", err)
+	}
+
+	if calls == 1 && seen == 500 {
+		t.Skip("the server ignores the SCAN count and returned every key in one batch")
+	}
+
+	if seen < 100 || seen >= 500 {
+		t.Fatalf("scan stopped after %d keys, want at least 100 and fewer than 500", seen)
+	}
+
+	total := 0
+	err = s.ScanWithProgress(ctx, "item:*", 20, func(keys []string, scanned int64) bool {
+		total = int(scanned)
+		return true
+	})
+	if err != nil || total != 500 {
+		t.Fatalf("full scan = %d, %v; want 500", total, err)
+	}
+
+	if calls < 2 {
+		t.Fatalf("aborted scan made %d calls, want several batches", calls)
+	}
+}
@@ -0,0 +1,162 @@
+package earedis
+
+import (
+	"context"
+	"errors"
+	"github.com/eris-apple/eactx"
+	"strings"
+	"sync"
+)
+
+// clusterSlots — the number of hash slots of a redis cluster.
+const clusterSlots = 16384
+
+// crc16Table — the CRC16-XMODEM table used by redis cluster key hashing.
+var crc16Table = func() [256]uint16 {
+	var table [256]uint16
+	for i := range table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+
+	return table
+}()
+
+// keySlot — returns the cluster hash slot of key, honouring "{hash tags}".
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^key[i]]
+	}
+
+	return int(crc) % clusterSlots
+}
+
+// keyChunk — a batch of keys along with their positions in the original list.
+type keyChunk struct {
+	keys    []string
+	indexes []int
+}
+
+// chunkKeys — splits keys into chunks of at most size keys. In cluster mode keys are first grouped
+// by hash slot, so that every chunk can be sent as a single multi-key command.
+func (s *Service) chunkKeys(keys []string, size int) []keyChunk {
+	if size <= 0 {
+		size = len(keys)
+	}
+
+	groups := [][]int{make([]int, len(keys))}
+	if s.isCluster() {
+		bySlot := make(map[int][]int)
+		order := make([]int, 0)
+		for i, key := range keys {
+			slot := keySlot(key)
+			if _, ok := bySlot[slot]; !ok {
+				order = append(order, slot)
+			}
+			bySlot[slot] = append(bySlot[slot], i)
+		}
+
+		groups = groups[:0]
+		for _, slot := range order {
+			groups = append(groups, bySlot[slot])
+		}
+	} else {
+		for i := range keys {
+			groups[0][i] = i
+		}
+	}
+
+	chunks := make([]keyChunk, 0)
+	for _, group := range groups {
+		for start := 0; start < len(group); start += size {
+			end := min(start+size, len(group))
+
+			chunk := keyChunk{indexes: group[start:end], keys: make([]string, 0, end-start)}
+			for _, i := range chunk.indexes {
+				chunk.keys = append(chunk.keys, keys[i])
+			}
+
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	return chunks
+}
+
+// deleteConcurrency — slot groups deleted at a time by Del and Unlink in cluster mode.
+const deleteConcurrency = 16
+
+// runChunks — calls fn for every chunk, at most concurrency at a time. With failFast the first error
+// cancels the chunks still pending and is returned alone; otherwise all chunks run and the errors are joined.
+func (s *Service) runChunks(ctx *eactx.Context, chunks []keyChunk, concurrency int, failFast bool, fn func(ctx context.Context, chunk keyChunk) error) error {
+	if len(chunks) == 1 {
+		return fn(ctx.GetContext(), chunks[0])
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	chunkCtx, cancel := context.WithCancel(ctx.GetContext())
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	sem := make(chan struct{}, concurrency)
+	for _, chunk := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-chunkCtx.Done():
+		}
+
+		if chunkCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(chunk keyChunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(chunkCtx, chunk); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+
+				if failFast {
+					cancel()
+				}
+			}
+		}(chunk)
+	}
+
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return ctx.Err()
+	}
+
+	if failFast {
+		return errs[0]
+	}
+
+	return errors.Join(errs...)
+}
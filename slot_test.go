@@ -0,0 +1,69 @@
+package earedis
+
+import (
+	"fmt"
+	rdb "github.com/redis/go-redis/v9"
+	"testing"
+)
+
+func TestKeySlot(t *testing.T) {
+	// The CRC16 check value, and the examples of the CLUSTER KEYSLOT documentation.
+	vectors := map[string]int{
+		"123456789":            12739,
+		"somekey":              11058,
+		"foo{hash_tag}":        2515,
+		"bar{hash_tag}":        2515,
+		"{user1000}.following": 3443,
+		"user1000":             3443,
+	}
+	for key, want := range vectors {
+		if got := keySlot(key); got != want {
+			t.Errorf("keySlot(%q) = %d, want %d", key, got, want)
+		}
+	}
+
+	// Only the first non-empty {...} is a hash tag.
+	if keySlot("foo{}{bar}") == keySlot("bar") {
+		t.Error("keySlot(foo{}{bar}) used the second braces as the hash tag")
+	}
+
+	if got, want := keySlot("foo{{bar}}zap"), keySlot("{bar"); got != want {
+		t.Errorf("keySlot(foo{{bar}}zap) = %d, want %d (the slot of {bar)", got, want)
+	}
+}
+
+func TestChunkKeysGroupsBySlotInCluster(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+	s.client = rdb.NewClusterClient(&rdb.ClusterOptions{Addrs: []string{"127.0.0.1:0"}})
+	t.Cleanup(func() { _ = s.client.Close() })
+
+	keys := make([]string, 5000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("{tag-%d}:key-%d", i%7, i)
+	}
+
+	seen := make([]bool, len(keys))
+	for _, chunk := range s.chunkKeys(keys, 300) {
+		if len(chunk.keys) > 300 || len(chunk.keys) != len(chunk.indexes) {
+			t.Fatalf("chunk of %d keys and %d indexes, want at most 300 of each", len(chunk.keys), len(chunk.indexes))
+		}
+
+		slot := keySlot(chunk.keys[0])
+		for i, key := range chunk.keys {
+			if keySlot(key) != slot {
+				t.Fatalf("chunk mixes slots %d and %d", slot, keySlot(key))
+			}
+
+			if keys[chunk.indexes[i]] != key || seen[chunk.indexes[i]] {
+				t.Fatalf("chunk index %d does not map to %s exactly once", chunk.indexes[i], key)
+			}
+			seen[chunk.indexes[i]] = true
+		}
+	}
+
+	for i, ok := range seen {
+		if !ok {
+			t.Fatalf("key %s missing from the chunks", keys[i])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package earedis
+
+import (
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+type SlowLog = rdb.SlowLog
+
+// SlowLogGet — returns up to count of the most recent slow log entries, newest first; a negative count returns all.
+// Each entry carries the execution Duration and the command Args.
+func (s *Service) SlowLogGet(ctx *eactx.Context, count int64) (logs []SlowLog, err error) {
+	defer s.reportReturned("SlowLogGet", "", &err)
+
+	if err := s.checkCommand("SLOWLOG GET"); err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.SlowLogGet(ctx.GetContext(), count).Result()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get slow log", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SlowLogLen — returns the number of entries in the slow log.
+func (s *Service) SlowLogLen(ctx *eactx.Context) (n int64, err error) {
+	defer s.reportReturned("SlowLogLen", "", &err)
+
+	if err := s.checkCommand("SLOWLOG LEN"); err != nil {
+		return 0, err
+	}
+
+	result, err := s.client.Do(ctx.GetContext(), "slowlog", "len").Int64()
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get slow log length", err)
+		return 0, err
+	}
+
+	return result, nil
+}
+
+// SlowLogReset — clears the slow log.
+// Refused with ErrCommandDisabled unless ConnectConfig.AllowAdminCommands is set.
+func (s *Service) SlowLogReset(ctx *eactx.Context) (err error) {
+	defer s.reportReturned("SlowLogReset", "", &err)
+
+	if err := s.checkCommand("SLOWLOG RESET"); err != nil {
+		return err
+	}
+
+	if err := s.client.Do(ctx.GetContext(), "slowlog", "reset").Err(); err != nil {
+		s.l.ErrorT(s.traceName, "Failed to reset slow log", err)
+		return err
+	}
+
+	s.l.WarnT(s.traceName, "Reset slow log")
+	return nil
+}
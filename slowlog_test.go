@@ -0,0 +1,78 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSlowLogGetEntries(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.AllowAdminCommands = true
+	})
+	ctx := testContext(t)
+
+	before, err := s.ConfigGet(ctx, "slowlog-log-slower-than")
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("ConfigGet: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = s.ConfigSet(testContext(t), "slowlog-log-slower-than", before["slowlog-log-slower-than"])
+	})
+
+	// Log every command.
+	if err := s.ConfigSet(ctx, "slowlog-log-slower-than", "0"); err != nil {
+		t.Fatalf("ConfigSet: %v", err)
+	}
+
+	if err := s.SlowLogReset(ctx); err != nil {
+		t.Fatalf("SlowLogReset: %v", err)
+	}
+
+	members := make([]interface{}, 1000)
+	for i := range members {
+		members[i] = fmt.Sprint("member-", i)
+	}
+
+	if err := s.client.SAdd(ctx.GetContext(), "slowlog-set", members...).Err(); err != nil {
+		t.Fatalf("SADD: %v", err)
+	}
+
+	entries, err := s.SlowLogGet(ctx, -1)
+	if err != nil {
+		t.Fatalf("SlowLogGet: %v", err)
+	}
+
+	for _, entry := range entries {
+		if len(entry.Args) < 2 || entry.Args[0] != "sadd" || entry.Args[1] != "slowlog-set" {
+			continue
+		}
+
+		if entry.Duration <= 0 {
+			t.Fatalf("SADD entry has duration %v, want > 0", entry.Duration)
+		}
+
+		if entry.Time.IsZero() {
+			t.Fatal("SADD entry has no time")
+		}
+
+		length, err := s.SlowLogLen(ctx)
+		if err != nil || length < 1 {
+			t.Fatalf("SlowLogLen = %d, %v; want at least 1", length, err)
+		}
+
+		return
+	}
+
+	t.Fatalf("no SADD entry among %d slow log entries", len(entries))
+}
+
+func TestSlowLogResetDeniedByDefault(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+
+	if err := s.SlowLogReset(testContext(t)); !errors.Is(err, ErrCommandDisabled) {
+		t.Fatalf("SlowLogReset error = %v, want ErrCommandDisabled", err)
+	}
+}
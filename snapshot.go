@@ -0,0 +1,48 @@
+package earedis
+
+import (
+	"errors"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+)
+
+// Snapshot — copies every key to destPrefix+key (COPY ... REPLACE) inside a single MULTI/EXEC,
+// so the copies reflect one point in time and can be exported later without racing writers.
+// Missing source keys are skipped by Redis and leave no destination key. destPrefix must not be empty.
+// In cluster mode every source and destination key must hash to the same slot
+// (use a hash tag such as "{tenant}" in both the keys and destPrefix), otherwise the transaction fails with CROSSSLOT.
+func (s *Service) Snapshot(ctx *eactx.Context, keys []string, destPrefix string) (err error) {
+	defer s.reportReturned("Snapshot", "", &err)
+
+	if err := s.checkCommand("COPY"); err != nil {
+		return err
+	}
+
+	// An empty prefix would copy every key onto itself.
+	if destPrefix == "" {
+		return errors.New("earedis: invalid snapshot prefix, destPrefix must not be empty")
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	tenant, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Copy(ctx.GetContext(), tenant+key, tenant+destPrefix+key, s.c.DB, true)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to snapshot keys", keys, err)
+		return err
+	}
+
+	return nil
+}
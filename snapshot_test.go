@@ -0,0 +1,47 @@
+package earedis
+
+import "testing"
+
+func TestSnapshotCopiesPointInTime(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	want := map[string]string{"a": "1", "b": "2", "c": "3"}
+	for key, value := range want {
+		if err := s.Set(ctx, key, value, 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	err := s.Snapshot(ctx, []string{"a", "b", "c", "missing"}, "snap:")
+	skipUnsupported(t, err)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	// Writes after the snapshot must not reach the copies.
+	for key := range want {
+		if err := s.Set(ctx, key, "changed", 0); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	for key, value := range want {
+		got, err := s.Get(ctx, "snap:"+key)
+		if err != nil || got != value {
+			t.Fatalf("snap:%s = %q, %v; want %q", key, got, err, value)
+		}
+	}
+
+	if n, err := s.client.Exists(ctx.GetContext(), "snap:missing").Result(); err != nil || n != 0 {
+		t.Fatalf("snap:missing exists = %d, %v; want 0", n, err)
+	}
+}
+
+func TestSnapshotRejectsEmptyPrefix(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+
+	if err := s.Snapshot(testContext(t), []string{"key"}, ""); err == nil {
+		t.Fatal("Snapshot with an empty prefix succeeded")
+	}
+}
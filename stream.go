@@ -0,0 +1,73 @@
+package earedis
+
+import (
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"io"
+)
+
+// streamChunkSize — bytes read per GETRANGE by GetStream.
+const streamChunkSize = 64 * 1024
+
+// GetStream — writes the value of key to w in 64 KiB GETRANGE chunks, so large values are never held
+// in memory at once, and returns the number of bytes written. A missing key returns ErrNotFound.
+// The chunks are separate reads: a value overwritten during the stream may be copied partly old, partly new.
+func (s *Service) GetStream(ctx *eactx.Context, key string, w io.Writer) (n int64, err error) {
+	defer s.reportReturned("GetStream", key, &err)
+
+	if err := s.checkCommand("GETRANGE"); err != nil {
+		return 0, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.checkKey(key); err != nil {
+		return 0, err
+	}
+
+	var exists *rdb.IntCmd
+	var strlen *rdb.IntCmd
+	_, err = s.client.Pipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		exists = pipe.Exists(ctx.GetContext(), key)
+		strlen = pipe.StrLen(ctx.GetContext(), key)
+
+		return nil
+	})
+	if err != nil {
+		s.recordKey(key, err)
+		s.l.ErrorT(s.traceName, "Failed to get key length", key, err)
+		return 0, err
+	}
+
+	if exists.Val() == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	var written int64
+	for size := strlen.Val(); written < size; {
+		chunk, err := s.client.GetRange(ctx.GetContext(), key, written, min(written+streamChunkSize, size)-1).Result()
+		if err != nil {
+			s.recordKey(key, err)
+			s.l.ErrorT(s.traceName, "Failed to stream key", key, err)
+			return written, err
+		}
+
+		// The value shrank or vanished since STRLEN.
+		if len(chunk) == 0 {
+			break
+		}
+
+		n, err := io.WriteString(w, chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	s.recordKey(key, nil)
+	return written, nil
+}
@@ -0,0 +1,47 @@
+package earedis
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestGetStreamLargeValue(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	// Several chunks plus a partial one, with bytes of every value.
+	value := make([]byte, 3*streamChunkSize+12345)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range value {
+		value[i] = byte(rng.UintN(256))
+	}
+
+	if err := s.Set(ctx, "blob", value, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := s.GetStream(ctx, "blob", &buf)
+	if err != nil {
+		t.Fatalf("GetStream: %v", err)
+	}
+
+	if n != int64(len(value)) || !bytes.Equal(buf.Bytes(), value) {
+		t.Fatalf("GetStream wrote %d bytes, want the %d stored bytes unchanged", n, len(value))
+	}
+
+	if err := s.Set(ctx, "empty", "", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	buf.Reset()
+	if n, err := s.GetStream(ctx, "empty", &buf); err != nil || n != 0 || buf.Len() != 0 {
+		t.Fatalf("GetStream of an empty value = %d, %v; want 0", n, err)
+	}
+
+	if _, err := s.GetStream(ctx, "missing", &buf); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetStream of a missing key: %v, want ErrNotFound", err)
+	}
+}
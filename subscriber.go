@@ -0,0 +1,154 @@
+package earedis
+
+import (
+	"encoding/json"
+	"github.com/eris-apple/eactx"
+	"sync"
+	"sync/atomic"
+)
+
+// BackpressurePolicy — what a JSONSubscription does with a message when its buffer is full.
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock — wait for the consumer; messages pile up in go-redis, which drops them after a minute.
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropOldest — discard the oldest buffered message to make room.
+	BackpressureDropOldest
+	// BackpressureDropNewest — discard the incoming message.
+	BackpressureDropNewest
+	// BackpressureError — close the subscription; Err then returns ErrBackpressure.
+	BackpressureError
+)
+
+const defaultSubscriptionBuffer = 100
+
+// SubscribeOptions — the buffering of a JSONSubscription.
+type SubscribeOptions struct {
+	// BufferSize — messages buffered for the consumer. Defaults to 100.
+	BufferSize int
+	// Policy — the behavior once the buffer is full. Defaults to BackpressureBlock.
+	Policy BackpressurePolicy
+}
+
+// JSONSubscription — a subscription delivering message payloads decoded from JSON into T.
+type JSONSubscription[T any] struct {
+	pubsub *PubSub
+	out    chan T
+
+	done      chan struct{}
+	closeOnce sync.Once
+
+	dropped atomic.Uint64
+
+	mu  sync.Mutex
+	err error
+}
+
+// JSONSubscribe — subscribes to channels (see Service.Subscribe) and delivers every payload decoded into T,
+// buffered and subject to opts.Policy. Payloads that fail to decode are logged and skipped.
+// The caller must Close the subscription.
+func JSONSubscribe[T any](s *Service, ctx *eactx.Context, opts SubscribeOptions, channels ...string) (sub *JSONSubscription[T], err error) {
+	defer s.reportReturned("JSONSubscribe", "", &err)
+
+	pubsub, err := s.subscribe(ctx, channels...)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultSubscriptionBuffer
+	}
+
+	sub = &JSONSubscription[T]{
+		pubsub: pubsub,
+		out:    make(chan T, opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+
+	go sub.run(s, opts.Policy)
+	return sub, nil
+}
+
+// run — decodes incoming messages and hands them to the consumer until the subscription closes.
+func (sub *JSONSubscription[T]) run(s *Service, policy BackpressurePolicy) {
+	defer close(sub.out)
+
+	for msg := range sub.pubsub.Channel() {
+		var v T
+		if err := json.Unmarshal([]byte(msg.Payload), &v); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to decode message from channel", msg.Channel, err)
+			continue
+		}
+
+		switch policy {
+		case BackpressureDropNewest:
+			select {
+			case sub.out <- v:
+			default:
+				sub.dropped.Add(1)
+			}
+		case BackpressureDropOldest:
+			select {
+			case sub.out <- v:
+			default:
+				select {
+				case <-sub.out:
+					sub.dropped.Add(1)
+				default:
+				}
+
+				// This goroutine is the only sender, so the freed space is still there.
+				select {
+				case sub.out <- v:
+				default:
+					sub.dropped.Add(1)
+				}
+			}
+		case BackpressureError:
+			select {
+			case sub.out <- v:
+			default:
+				s.l.ErrorT(s.traceName, "Closing subscription of slow consumer", msg.Channel)
+				sub.fail(ErrBackpressure)
+				_ = sub.pubsub.Close()
+				return
+			}
+		default:
+			select {
+			case sub.out <- v:
+			case <-sub.done:
+				return
+			}
+		}
+	}
+}
+
+func (sub *JSONSubscription[T]) fail(err error) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	sub.err = err
+}
+
+// Messages — returns the channel of decoded payloads, closed when the subscription ends.
+func (sub *JSONSubscription[T]) Messages() <-chan T {
+	return sub.out
+}
+
+// Dropped — returns the number of messages discarded by the drop policies.
+func (sub *JSONSubscription[T]) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Err — returns ErrBackpressure once BackpressureError has closed the subscription, nil otherwise.
+func (sub *JSONSubscription[T]) Err() error {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.err
+}
+
+// Close — ends the subscription, also releasing a delivery blocked on the consumer.
+func (sub *JSONSubscription[T]) Close() error {
+	sub.closeOnce.Do(func() { close(sub.done) })
+	return sub.pubsub.Close()
+}
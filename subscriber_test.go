@@ -0,0 +1,127 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type subscriberEvent struct {
+	N int `json:"n"`
+}
+
+// publishEvents — publishes the events 1..n to channel.
+func publishEvents(t *testing.T, s *Service, channel string, n int) {
+	t.Helper()
+
+	for i := 1; i <= n; i++ {
+		if err := s.Publish(testContext(t), channel, fmt.Sprintf(`{"n":%d}`, i)); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+}
+
+// waitDropped — waits until the subscription has dropped want messages.
+func waitDropped(t *testing.T, sub *JSONSubscription[subscriberEvent], want uint64) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sub.Dropped() < want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := sub.Dropped(); got != want {
+		t.Fatalf("dropped %d messages, want %d", got, want)
+	}
+}
+
+// drain — receives the buffered events, giving up once none arrive for a while.
+func drain(sub *JSONSubscription[subscriberEvent]) []int {
+	var got []int
+	for {
+		select {
+		case event, ok := <-sub.Messages():
+			if !ok {
+				return got
+			}
+			got = append(got, event.N)
+		case <-time.After(200 * time.Millisecond):
+			return got
+		}
+	}
+}
+
+func TestJSONSubscribeBackpressure(t *testing.T) {
+	tests := []struct {
+		policy  BackpressurePolicy
+		dropped uint64
+		want    []int
+		err     error
+	}{
+		{BackpressureDropNewest, 6, []int{1, 2, 3}, nil},
+		{BackpressureDropOldest, 6, []int{7, 8, 9}, nil},
+		{BackpressureError, 0, []int{1, 2, 3}, ErrBackpressure},
+	}
+
+	for _, tt := range tests {
+		s := newTestService(t)
+		ctx := testContext(t)
+
+		sub, err := JSONSubscribe[subscriberEvent](s, ctx, SubscribeOptions{BufferSize: 3, Policy: tt.policy}, "events")
+		if err != nil {
+			t.Fatalf("JSONSubscribe: %v", err)
+		}
+
+		// The consumer reads nothing until every event is published.
+		publishEvents(t, s, "events", 9)
+
+		if tt.err != nil {
+			deadline := time.Now().Add(5 * time.Second)
+			for sub.Err() == nil && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if !errors.Is(sub.Err(), tt.err) {
+				t.Fatalf("policy %d: Err = %v, want %v", tt.policy, sub.Err(), tt.err)
+			}
+		}
+
+		waitDropped(t, sub, tt.dropped)
+
+		if got := drain(sub); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("policy %d: received %v, want %v", tt.policy, got, tt.want)
+		}
+
+		_ = sub.Close()
+	}
+}
+
+func TestJSONSubscribeBlockDeliversEverything(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	sub, err := JSONSubscribe[subscriberEvent](s, ctx, SubscribeOptions{BufferSize: 1, Policy: BackpressureBlock}, "events")
+	if err != nil {
+		t.Fatalf("JSONSubscribe: %v", err)
+	}
+	defer sub.Close()
+
+	publishEvents(t, s, "events", 9)
+
+	var got []int
+	for len(got) < 9 {
+		select {
+		case event := <-sub.Messages():
+			got = append(got, event.N)
+			time.Sleep(20 * time.Millisecond)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %v, then nothing", got)
+		}
+	}
+
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(got, want) || sub.Dropped() != 0 {
+		t.Fatalf("received %v with %d dropped, want %v and none", got, sub.Dropped(), want)
+	}
+}
@@ -0,0 +1,145 @@
+package earedis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"sync"
+	"time"
+)
+
+// swrEntry — a value stored by GetStaleWhileRevalidate together with the time it was loaded.
+type swrEntry struct {
+	Value    string `json:"value"`
+	StoredAt int64  `json:"stored_at"`
+}
+
+// flight — one in-progress load shared by every caller asking for the same key.
+type flight struct {
+	wg    sync.WaitGroup
+	value string
+	err   error
+}
+
+// flightGroup — deduplicates concurrent loads of the same key within this process.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do — runs fn for key unless a load of key is already running, in which case its result is awaited.
+func (g *flightGroup) do(key string, fn func() (string, error)) (string, error) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		f.wg.Wait()
+		return f.value, f.err
+	}
+
+	// Seen by the waiters only if fn panics.
+	f := &flight{err: fmt.Errorf("earedis: load of %s panicked", key)}
+	f.wg.Add(1)
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+
+		f.wg.Done()
+	}()
+
+	f.value, f.err = fn()
+	return f.value, f.err
+}
+
+// GetStaleWhileRevalidate — returns the value at key, serving it stale while it is refreshed:
+// within freshTTL of being loaded the value is returned as fresh; within the following staleTTL it is
+// returned with stale true and loader refreshes it in the background; afterwards, or when the key is
+// missing, loader runs synchronously and its value is returned. Loads of a key are single-flighted
+// within the process. Values are stored in an envelope with their load time and only readable through this method.
+func (s *Service) GetStaleWhileRevalidate(ctx *eactx.Context, key string, freshTTL, staleTTL time.Duration, loader func() (string, error)) (value string, stale bool, err error) {
+	defer s.reportReturned("GetStaleWhileRevalidate", key, &err)
+
+	if err := s.checkCommand("GET"); err != nil {
+		return "", false, err
+	}
+
+	if err := s.checkCommand("SET"); err != nil {
+		return "", false, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return "", false, err
+	}
+
+	load := func(ctx *eactx.Context) (string, error) {
+		return s.flights.do(key, func() (string, error) {
+			value, err := loader()
+			if err != nil {
+				return "", err
+			}
+
+			entry, err := json.Marshal(swrEntry{Value: value, StoredAt: s.clock.Now().UnixMilli()})
+			if err != nil {
+				return "", err
+			}
+
+			if err := s.set(ctx, key, entry, freshTTL+staleTTL); err != nil {
+				return "", err
+			}
+
+			return value, nil
+		})
+	}
+
+	data, err := s.client.Get(ctx.GetContext(), key).Bytes()
+	if err != nil && !errors.Is(err, rdb.Nil) {
+		s.l.ErrorT(s.traceName, "Failed to get key", key, err)
+		return "", false, err
+	}
+
+	if err == nil {
+		var entry swrEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			s.l.ErrorT(s.traceName, "Failed to decode key, reloading", key, err)
+		} else {
+			age := s.clock.Now().Sub(time.UnixMilli(entry.StoredAt))
+			switch {
+			case age < freshTTL:
+				return entry.Value, false, nil
+			case age < freshTTL+staleTTL:
+				// The refresh must outlive the request that triggered it.
+				go func() {
+					// The flight re-panics a panicking loader; here nobody is left to handle it.
+					defer func() {
+						if r := recover(); r != nil {
+							s.l.ErrorT(s.traceName, "Revalidation of key panicked", key, r)
+						}
+					}()
+
+					refreshCtx := eactx.NewContextWithCancel(context.WithoutCancel(ctx.GetContext()))
+					defer refreshCtx.Cancel()
+
+					if _, err := load(refreshCtx); err != nil {
+						s.l.ErrorT(s.traceName, "Failed to revalidate key", key, err)
+					}
+				}()
+
+				return entry.Value, true, nil
+			}
+		}
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, false, nil
+}
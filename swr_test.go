@@ -0,0 +1,188 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetStaleWhileRevalidate(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	clock := &fakeClock{now: time.Now()}
+	s.SetClock(clock)
+
+	var loads atomic.Int64
+	refreshed := make(chan struct{}, 1)
+	loader := func() (string, error) {
+		n := loads.Add(1)
+		defer func() {
+			select {
+			case refreshed <- struct{}{}:
+			default:
+			}
+		}()
+
+		return fmt.Sprint("v", n), nil
+	}
+
+	get := func(wantValue string, wantStale bool, wantLoads int64) {
+		t.Helper()
+
+		value, stale, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, loader)
+		if err != nil || value != wantValue || stale != wantStale {
+			t.Fatalf("GetStaleWhileRevalidate = %q, %v, %v; want %q, %v", value, stale, err, wantValue, wantStale)
+		}
+
+		if got := loads.Load(); got != wantLoads {
+			t.Fatalf("loader ran %d times, want %d", got, wantLoads)
+		}
+	}
+
+	// Missing: loaded synchronously.
+	get("v1", false, 1)
+	<-refreshed
+
+	// Fresh: served without loading.
+	clock.advance(5 * time.Second)
+	get("v1", false, 1)
+
+	// Stale: served at once and refreshed in the background.
+	clock.advance(10 * time.Second)
+	value, stale, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, loader)
+	if err != nil || value != "v1" || !stale {
+		t.Fatalf("stale GetStaleWhileRevalidate = %q, %v, %v; want \"v1\", true", value, stale, err)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stale value was not revalidated")
+	}
+
+	// The refresh is stored with its own load time, so it is fresh again.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		value, stale, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, loader)
+		if err == nil && value == "v2" && !stale {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("after revalidation = %q, %v, %v; want \"v2\", false", value, stale, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Expired: blocks on the loader.
+	clock.advance(2 * time.Minute)
+	get("v3", false, 3)
+	<-refreshed
+
+	// An expired value is never served when the loader fails.
+	clock.advance(2 * time.Minute)
+	failing := func() (string, error) { return "", errors.New("origin down") }
+	if value, _, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, failing); err == nil {
+		t.Fatalf("expired value %q served although the loader failed", value)
+	}
+}
+
+func TestFlightGroupSurvivesPanic(t *testing.T) {
+	g := &flightGroup{flights: make(map[string]*flight)}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer func() { _ = recover() }()
+
+		_, _ = g.do("key", func() (string, error) {
+			close(started)
+			<-release
+			panic("loader bug")
+		})
+	}()
+
+	<-started
+
+	var wg sync.WaitGroup
+	var waitErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, waitErr = g.do("key", func() (string, error) { return "unexpected", nil })
+	}()
+
+	// Give the waiter time to join the running flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter deadlocked on a panicked load")
+	}
+
+	if waitErr == nil {
+		t.Fatal("waiter got no error from the panicked load")
+	}
+
+	if value, err := g.do("key", func() (string, error) { return "fresh", nil }); err != nil || value != "fresh" {
+		t.Fatalf("load after the panic = %q, %v; want \"fresh\"", value, err)
+	}
+}
+
+func TestGetStaleWhileRevalidateSurvivesPanickingRefresh(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	clock := &fakeClock{now: time.Now()}
+	s.SetClock(clock)
+
+	if _, _, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, func() (string, error) { return "v1", nil }); err != nil {
+		t.Fatalf("initial GetStaleWhileRevalidate: %v", err)
+	}
+
+	clock.advance(15 * time.Second)
+	panicked := make(chan struct{})
+	value, stale, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, func() (string, error) {
+		close(panicked)
+		panic("loader bug")
+	})
+	if err != nil || value != "v1" || !stale {
+		t.Fatalf("stale GetStaleWhileRevalidate = %q, %v, %v; want \"v1\", true", value, stale, err)
+	}
+
+	select {
+	case <-panicked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stale value was not revalidated")
+	}
+
+	// The process survived the panic and the stale value is still served.
+	time.Sleep(50 * time.Millisecond)
+	value, stale, err = s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, func() (string, error) { return "v2", nil })
+	if err != nil || value != "v1" || !stale {
+		t.Fatalf("GetStaleWhileRevalidate after the panic = %q, %v, %v; want \"v1\", true", value, stale, err)
+	}
+}
+
+func TestGetStaleWhileRevalidateHonorsMaxValueSize(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.MaxValueSize = 16
+	})
+	ctx := testContext(t)
+
+	_, _, err := s.GetStaleWhileRevalidate(ctx, "page", 10*time.Second, time.Minute, func() (string, error) { return "a value too large for the limit", nil })
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("GetStaleWhileRevalidate of an oversized value: %v, want ErrValueTooLarge", err)
+	}
+}
@@ -0,0 +1,37 @@
+package earedis
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// checkTarget — ensures v is a non-nil pointer that a decoded value can be stored into.
+func checkTarget(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return fmt.Errorf("%w: got nil, want a non-nil pointer", ErrInvalidTarget)
+	}
+
+	if rv.Kind() != reflect.Pointer {
+		return fmt.Errorf("%w: got %s, want a non-nil pointer", ErrInvalidTarget, rv.Kind())
+	}
+
+	if rv.IsNil() {
+		return fmt.Errorf("%w: got nil %s, want a non-nil pointer", ErrInvalidTarget, rv.Type())
+	}
+
+	return nil
+}
+
+// checkSliceTarget — ensures v is a non-nil pointer to a slice that decoded elements can be appended to.
+func checkSliceTarget(v interface{}) error {
+	if err := checkTarget(v); err != nil {
+		return err
+	}
+
+	if kind := reflect.ValueOf(v).Elem().Kind(); kind != reflect.Slice {
+		return fmt.Errorf("%w: got pointer to %s, want a pointer to a slice", ErrInvalidTarget, kind)
+	}
+
+	return nil
+}
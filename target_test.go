@@ -0,0 +1,55 @@
+package earedis
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInvalidTargets(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+	ctx := testContext(t)
+
+	type doc struct {
+		Name string `json:"name"`
+	}
+
+	var (
+		nilPointer *doc
+		value      doc
+		notSlice   doc
+	)
+
+	_, withTTLErr := s.JSONGetWithTTL(ctx, "key", value)
+
+	tests := []struct {
+		name string
+		err  error
+		kind string
+	}{
+		{"JSONGet nil", s.JSONGet(ctx, "key", nil), "nil"},
+		{"JSONGet nil pointer", s.JSONGet(ctx, "key", nilPointer), "nil *earedis.doc"},
+		{"JSONGet non-pointer", s.JSONGet(ctx, "key", value), "struct"},
+		{"JSONGetBatch non-pointer", s.JSONGetBatch(ctx, map[string]interface{}{"key": value}), "struct"},
+		{"JSONUpdate nil pointer", s.JSONUpdate(ctx, "key", nilPointer, func() error { return nil }), "nil *earedis.doc"},
+		{"JSONGetWithTTL non-pointer", withTTLErr, "struct"},
+		{"JSONSMembersWithChild non-pointer", s.JSONSMembersWithChild(ctx, "key", []doc{}), "slice"},
+		{"JSONSMembersWithChild pointer to non-slice", s.JSONSMembersWithChild(ctx, "key", &notSlice), "pointer to struct"},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, ErrInvalidTarget) {
+			t.Errorf("%s: %v, want ErrInvalidTarget", tt.name, tt.err)
+			continue
+		}
+
+		if !strings.Contains(tt.err.Error(), "got "+tt.kind+",") {
+			t.Errorf("%s: %q does not report the observed kind %q", tt.name, tt.err, tt.kind)
+		}
+	}
+
+	var docs []doc
+	if err := checkSliceTarget(&docs); err != nil {
+		t.Fatalf("checkSliceTarget(&[]doc): %v", err)
+	}
+}
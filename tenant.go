@@ -0,0 +1,94 @@
+package earedis
+
+import (
+	"fmt"
+	"github.com/eris-apple/eactx"
+	"strings"
+)
+
+// tenantReserved — characters a tenant may not contain: the namespace separator and the SCAN glob characters.
+const tenantReserved = ":*?[]\\"
+
+// tenantPrefix — returns "<tenant>:" for the tenant carried by ctx, or "" when no ConnectConfig.TenantExtractor is set.
+// Without a tenant in ctx it returns ErrNoTenant, unless ConnectConfig.AllowMissingTenant is set; a tenant with
+// reserved characters returns ErrInvalidTenant.
+func (s *Service) tenantPrefix(ctx *eactx.Context) (string, error) {
+	if s.c.TenantExtractor == nil {
+		return "", nil
+	}
+
+	tenant, ok := s.c.TenantExtractor(ctx.GetContext())
+	if !ok || tenant == "" {
+		if s.c.AllowMissingTenant {
+			return "", nil
+		}
+
+		s.l.ErrorT(s.traceName, "Rejected operation without tenant")
+		return "", ErrNoTenant
+	}
+
+	if strings.ContainsAny(tenant, tenantReserved) {
+		s.l.ErrorT(s.traceName, "Rejected tenant with reserved characters", tenant)
+		return "", fmt.Errorf("%w: %q", ErrInvalidTenant, tenant)
+	}
+
+	return tenant + ":", nil
+}
+
+// requireNamespace — returns ErrNotNamespaced for an operation that bypasses the tenant namespace
+// while ConnectConfig.TenantExtractor is set.
+func (s *Service) requireNamespace(operation string) error {
+	if s.c.TenantExtractor == nil {
+		return nil
+	}
+
+	s.l.ErrorT(s.traceName, "Rejected operation outside the tenant namespace", operation)
+	return fmt.Errorf("%w: %s", ErrNotNamespaced, operation)
+}
+
+// tenantKey — namespaces key with the tenant of ctx.
+func (s *Service) tenantKey(ctx *eactx.Context, key string) (string, error) {
+	prefix, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + key, nil
+}
+
+// tenantKeys — namespaces every key with the tenant of ctx.
+func (s *Service) tenantKeys(ctx *eactx.Context, keys []string) ([]string, error) {
+	prefix, err := s.tenantPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return prefixed(prefix, keys), nil
+}
+
+// prefixed — prepends prefix to every key.
+func prefixed(prefix string, keys []string) []string {
+	if prefix == "" {
+		return keys
+	}
+
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		result[i] = prefix + key
+	}
+
+	return result
+}
+
+// stripTenant — removes the tenant namespace from keys returned by redis.
+func stripTenant(prefix string, keys []string) []string {
+	if prefix == "" {
+		return keys
+	}
+
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, prefix)
+	}
+
+	return keys
+}
@@ -0,0 +1,114 @@
+package earedis
+
+import (
+	"context"
+	"errors"
+	"github.com/eris-apple/eactx"
+	"testing"
+)
+
+type tenantContextKey struct{}
+
+func tenantExtractor(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok
+}
+
+func tenantContext(t *testing.T, tenant string) *eactx.Context {
+	ctx := eactx.NewContextWithCancel(context.WithValue(context.Background(), tenantContextKey{}, tenant))
+	t.Cleanup(ctx.Cancel)
+	return ctx
+}
+
+func TestTenantIsolation(t *testing.T) {
+	s := newTestService(t, func(c *ConnectConfig) {
+		c.TenantExtractor = tenantExtractor
+	})
+	a := tenantContext(t, "a")
+	b := tenantContext(t, "b")
+
+	for ctx, value := range map[*eactx.Context]string{a: "value-a", b: "value-b"} {
+		if err := s.Set(ctx, "key", value, 0); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		if err := s.Set(ctx, "child:1", value, 0); err != nil {
+			t.Fatalf("Set child: %v", err)
+		}
+
+		if err := s.SAdd(ctx, "parent", "child:1"); err != nil {
+			t.Fatalf("SAdd: %v", err)
+		}
+	}
+
+	for ctx, want := range map[*eactx.Context]string{a: "value-a", b: "value-b"} {
+		if got, err := s.Get(ctx, "key"); err != nil || got != want {
+			t.Fatalf("Get = %q, %v; want %q", got, err, want)
+		}
+
+		children, err := s.SMembersWithChild(ctx, "parent")
+		if err != nil {
+			t.Fatalf("SMembersWithChild: %v", err)
+		}
+
+		if len(children) != 1 || children[0] != want {
+			t.Fatalf("SMembersWithChild = %v, want [%s]", children, want)
+		}
+	}
+
+	raw, err := s.client.Get(testContext(t).GetContext(), "a:key").Result()
+	if err != nil || raw != "value-a" {
+		t.Fatalf("raw a:key = %q, %v; want \"value-a\"", raw, err)
+	}
+
+	if _, err := s.Get(testContext(t), "key"); !errors.Is(err, ErrNoTenant) {
+		t.Fatalf("Get without tenant: %v, want ErrNoTenant", err)
+	}
+}
+
+func TestTenantRefusesRawAccess(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{TenantExtractor: tenantExtractor}, "test")
+	ctx := tenantContext(t, "a")
+
+	if _, err := s.BlockingClient(); !errors.Is(err, ErrNotNamespaced) {
+		t.Fatalf("BlockingClient: %v, want ErrNotNamespaced", err)
+	}
+
+	pipe := s.NewResilientPipeline(0, 0)
+	pipe.Do(ctx, "get", "key")
+	if _, err := pipe.Exec(ctx); !errors.Is(err, ErrNotNamespaced) {
+		t.Fatalf("ResilientPipeline.Exec: %v, want ErrNotNamespaced", err)
+	}
+
+	if err := s.SPublish(ctx, "channel", "message"); !errors.Is(err, ErrNotNamespaced) {
+		t.Fatalf("SPublish: %v, want ErrNotNamespaced", err)
+	}
+
+	if _, err := s.SSubscribe(ctx, "channel"); !errors.Is(err, ErrNotNamespaced) {
+		t.Fatalf("SSubscribe: %v, want ErrNotNamespaced", err)
+	}
+
+	if err := s.FlushDB(ctx); !errors.Is(err, ErrNotNamespaced) {
+		t.Fatalf("FlushDB: %v, want ErrNotNamespaced", err)
+	}
+}
+
+func TestTenantRejectsReservedCharacters(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{TenantExtractor: tenantExtractor}, "test")
+
+	// "a" + "b:x" would share the key of "a:b" + "x".
+	if _, err := s.tenantKey(tenantContext(t, "a:b"), "x"); !errors.Is(err, ErrInvalidTenant) {
+		t.Fatalf("tenantKey with a separator in the tenant: %v, want ErrInvalidTenant", err)
+	}
+
+	if key, err := s.tenantKey(tenantContext(t, "a"), "b:x"); err != nil || key != "a:b:x" {
+		t.Fatalf("tenantKey = %q, %v; want \"a:b:x\"", key, err)
+	}
+
+	// A glob tenant would widen SCAN patterns into other tenants' keys.
+	for _, tenant := range []string{"*", "a?", "[ab]", `a\`} {
+		if _, err := s.CountKeys(tenantContext(t, tenant), "*", 0); !errors.Is(err, ErrInvalidTenant) {
+			t.Fatalf("CountKeys for tenant %q: %v, want ErrInvalidTenant", tenant, err)
+		}
+	}
+}
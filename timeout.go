@@ -0,0 +1,28 @@
+package earedis
+
+import (
+	rdb "github.com/redis/go-redis/v9"
+	"time"
+)
+
+// WithTimeout — returns a shallow copy of the service whose commands use d as the read and write timeout,
+// for running a known-slow command without changing the global config. The copy shares the connection
+// pool of the original, so it must not be Disconnected. In cluster mode go-redis offers no per-client
+// timeout override, so the copy keeps the configured timeouts and a warning is logged.
+// Returns ErrNotInitialized before Init.
+func (s *Service) WithTimeout(d time.Duration) (timed *Service, err error) {
+	defer s.reportReturned("WithTimeout", "", &err)
+
+	clone := *s
+
+	switch client := s.client.(type) {
+	case nil:
+		return nil, ErrNotInitialized
+	case *rdb.Client:
+		clone.client = client.WithTimeout(d)
+	default:
+		s.l.WarnT(s.traceName, "Timeout override is not supported in cluster mode", d)
+	}
+
+	return &clone, nil
+}
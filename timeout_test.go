@@ -0,0 +1,54 @@
+package earedis
+
+import (
+	"errors"
+	rdb "github.com/redis/go-redis/v9"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithTimeoutExtendsSlowCommand(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	// A BLPOP sent through Do keeps the client read timeout, so it stands in for a slow command.
+	slow := func(s *Service) error {
+		return s.client.Do(ctx.GetContext(), "blpop", "empty", "0.5").Err()
+	}
+
+	short, err := s.WithTimeout(100 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("WithTimeout: %v", err)
+	}
+
+	var netErr net.Error
+	if err := slow(short); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("slow command under a 100ms timeout: %v, want a network timeout", err)
+	}
+
+	extended, err := s.WithTimeout(2 * time.Second)
+	if err != nil {
+		t.Fatalf("WithTimeout: %v", err)
+	}
+
+	if err := slow(extended); !errors.Is(err, rdb.Nil) {
+		t.Fatalf("slow command under a 2s timeout: %v, want redis.Nil", err)
+	}
+
+	if err := extended.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("Set through the copy: %v", err)
+	}
+
+	if got, err := s.Get(ctx, "key"); err != nil || got != "value" {
+		t.Fatalf("Get through the original = %q, %v; want \"value\"", got, err)
+	}
+}
+
+func TestWithTimeoutBeforeInit(t *testing.T) {
+	s := NewService(testLogger, &ConnectConfig{}, "test")
+
+	if _, err := s.WithTimeout(time.Second); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("WithTimeout before Init: %v, want ErrNotInitialized", err)
+	}
+}
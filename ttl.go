@@ -0,0 +1,87 @@
+package earedis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"time"
+)
+
+// NoExpiry — the TTL reported by GetWithTTL and JSONGetWithTTL for a key without an expiry.
+const NoExpiry time.Duration = -1
+
+// GetWithTTL — returns the value of key together with its remaining lifetime in one round trip,
+// e.g. to mirror the key in a local cache with the same expiry. A key without an expiry reports NoExpiry;
+// a missing key returns ErrNotFound.
+func (s *Service) GetWithTTL(ctx *eactx.Context, key string) (value string, ttl time.Duration, err error) {
+	defer s.reportReturned("GetWithTTL", key, &err)
+
+	return s.getWithTTL(ctx, key)
+}
+
+// getWithTTL — GetWithTTL without error reporting, for methods building on it.
+func (s *Service) getWithTTL(ctx *eactx.Context, key string) (value string, ttl time.Duration, err error) {
+	if err := s.checkCommand("GET"); err != nil {
+		return "", 0, err
+	}
+
+	if err := s.checkCommand("PTTL"); err != nil {
+		return "", 0, err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := s.checkKey(key); err != nil {
+		return "", 0, err
+	}
+
+	var get *rdb.StringCmd
+	var pttl *rdb.DurationCmd
+	_, err = s.client.TxPipelined(ctx.GetContext(), func(pipe rdb.Pipeliner) error {
+		get = pipe.Get(ctx.GetContext(), key)
+		pttl = pipe.PTTL(ctx.GetContext(), key)
+
+		return nil
+	})
+	s.recordKey(key, err)
+	if errors.Is(err, rdb.Nil) {
+		return "", 0, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	if err != nil {
+		s.l.ErrorT(s.traceName, "Failed to get key with ttl", key, err)
+		return "", 0, err
+	}
+
+	ttl = pttl.Val()
+	if ttl < 0 {
+		ttl = NoExpiry
+	}
+
+	return get.Val(), ttl, nil
+}
+
+// JSONGetWithTTL — like GetWithTTL, but decodes the JSON value into v.
+func (s *Service) JSONGetWithTTL(ctx *eactx.Context, key string, v interface{}) (ttl time.Duration, err error) {
+	defer s.reportReturned("JSONGetWithTTL", key, &err)
+
+	if err := checkTarget(v); err != nil {
+		return 0, err
+	}
+
+	value, ttl, err := s.getWithTTL(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return 0, err
+	}
+
+	return ttl, nil
+}
@@ -0,0 +1,45 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetWithTTL(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext(t)
+
+	if err := s.Set(ctx, "session", `{"user":"ann"}`, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	value, ttl, err := s.GetWithTTL(ctx, "session")
+	if err != nil || value != `{"user":"ann"}` {
+		t.Fatalf("GetWithTTL = %q, %v; want the stored value", value, err)
+	}
+
+	if ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("ttl = %v, want within (0, 1m]", ttl)
+	}
+
+	var session struct {
+		User string `json:"user"`
+	}
+	ttl, err = s.JSONGetWithTTL(ctx, "session", &session)
+	if err != nil || session.User != "ann" || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("JSONGetWithTTL = %+v, %v, %v; want ann with a ttl within (0, 1m]", session, ttl, err)
+	}
+
+	if err := s.Set(ctx, "forever", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if _, ttl, err := s.GetWithTTL(ctx, "forever"); err != nil || ttl != NoExpiry {
+		t.Fatalf("GetWithTTL of a key without expiry: ttl %v, %v; want NoExpiry", ttl, err)
+	}
+
+	if _, _, err := s.GetWithTTL(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetWithTTL of a missing key: %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,105 @@
+package earedis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/eris-apple/eactx"
+	rdb "github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+)
+
+const defaultPollInterval = 100 * time.Millisecond
+
+// WaitForKey — blocks until key exists and returns its value, or returns ErrTimeout once timeout elapses.
+// When the server publishes keyspace notifications for string commands ("K" with "$" or "A" in
+// notify-keyspace-events) the wait wakes up on writes to key; otherwise, and always in cluster mode,
+// it polls GET every pollInterval. Polling continues as a safety net in notification mode as well.
+// Cancelling ctx aborts the wait with the context error.
+func (s *Service) WaitForKey(ctx *eactx.Context, key string, pollInterval, timeout time.Duration) (value string, err error) {
+	defer s.reportReturned("WaitForKey", key, &err)
+
+	if err := s.checkCommand("GET"); err != nil {
+		return "", err
+	}
+
+	key, err = s.tenantKey(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	// Subscribe before the first GET, so a write between the two is not missed.
+	var notify <-chan *rdb.Message
+	if pubsub := s.keyspaceSubscription(ctx, key); pubsub != nil {
+		defer pubsub.Close()
+		notify = pubsub.Channel()
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		value, err := s.client.Get(ctx.GetContext(), key).Result()
+		if err == nil {
+			return value, nil
+		}
+
+		if !errors.Is(err, rdb.Nil) {
+			s.l.ErrorT(s.traceName, "Failed to get key", key, err)
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timer.C:
+			return "", fmt.Errorf("%w: waiting for key %s", ErrTimeout, key)
+		case <-ticker.C:
+		case _, ok := <-notify:
+			if !ok {
+				notify = nil
+			}
+		}
+	}
+}
+
+// keyspaceSubscription — subscribes to the keyspace notifications of key if the server emits them,
+// returning nil when polling has to be used instead.
+func (s *Service) keyspaceSubscription(ctx *eactx.Context, key string) *rdb.PubSub {
+	// Notifications are node-local, while cluster subscriptions are routed by channel slot.
+	if s.isCluster() {
+		return nil
+	}
+
+	// Without CONFIG GET or SUBSCRIBE permission, polling still works.
+	if s.checkCommand("CONFIG GET") != nil || s.checkCommand("SUBSCRIBE") != nil {
+		return nil
+	}
+
+	config, err := s.client.ConfigGet(ctx.GetContext(), "notify-keyspace-events").Result()
+	if err != nil {
+		s.l.DebugT(s.traceName, "Keyspace notifications unavailable, polling", key, err)
+		return nil
+	}
+
+	flags := config["notify-keyspace-events"]
+	if !strings.Contains(flags, "K") || !strings.ContainsAny(flags, "A$") {
+		return nil
+	}
+
+	pubsub := s.client.Subscribe(ctx.GetContext(), fmt.Sprintf("__keyspace@%d__:%s", s.c.DB, key))
+	if _, err := pubsub.Receive(ctx.GetContext()); err != nil {
+		s.l.DebugT(s.traceName, "Keyspace subscription failed, polling", key, err)
+		_ = pubsub.Close()
+		return nil
+	}
+
+	return pubsub
+}
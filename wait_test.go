@@ -0,0 +1,41 @@
+package earedis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testWaitForKeyWrittenMidway(t *testing.T, s *Service) {
+	ctx := testContext(t)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		if err := s.Set(ctx, "ready", "yes", 0); err != nil {
+			t.Errorf("Set: %v", err)
+		}
+	}()
+
+	got, err := s.WaitForKey(ctx, "ready", 50*time.Millisecond, 5*time.Second)
+	if err != nil || got != "yes" {
+		t.Fatalf("WaitForKey = %q, %v; want \"yes\"", got, err)
+	}
+}
+
+func TestWaitForKeyWrittenMidway(t *testing.T) {
+	testWaitForKeyWrittenMidway(t, newTestService(t))
+}
+
+func TestWaitForKeyWithConfigDisallowed(t *testing.T) {
+	testWaitForKeyWrittenMidway(t, newTestService(t, func(c *ConnectConfig) {
+		c.DisallowedCommands = []string{"CONFIG"}
+	}))
+}
+
+func TestWaitForKeyTimeout(t *testing.T) {
+	s := newTestService(t)
+
+	if _, err := s.WaitForKey(testContext(t), "never", 20*time.Millisecond, 100*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("WaitForKey error = %v, want ErrTimeout", err)
+	}
+}